@@ -0,0 +1,166 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"gopkg.in/yaml.v2"
+
+	"terraform-provider-servicepipe/internal/pkg/sdkv1/backup"
+)
+
+// accountCommands contains subcommands of the account command.
+var accountCommands = map[string]command{
+	"backup": {
+		usage:    "backup [-format json|yaml] [-file <path>]",
+		noOutput: true,
+		setup: func(flags *flag.FlagSet) func(context.Context, *cli, []string) error {
+			format := flags.String("format", outputJSON, "snapshot format: json or yaml")
+			file := flags.String("file", "", "file to write the snapshot to, standard output when empty")
+
+			return func(ctx context.Context, c *cli, _ []string) error {
+				return accountBackup(ctx, c, *format, *file)
+			}
+		},
+	},
+	"restore": {
+		usage: "restore <snapshot-file> [-dry-run]",
+		args:  1,
+		setup: func(flags *flag.FlagSet) func(context.Context, *cli, []string) error {
+			dryRun := flags.Bool("dry-run", false, "print the planned operations without changing anything")
+
+			return func(ctx context.Context, c *cli, args []string) error {
+				return accountRestore(ctx, c, args[0], *dryRun)
+			}
+		},
+	},
+}
+
+func accountBackup(ctx context.Context, c *cli, format, file string) error {
+	if format != outputJSON && format != outputYAML {
+		return fmt.Errorf("%w: unknown snapshot format %q", errUsage, format)
+	}
+
+	snapshot, responseResult, err := backup.Backup(ctx, c.client)
+	if err != nil {
+		return checkResponse(responseResult, fmt.Errorf("could not back up the account: %w", err))
+	}
+
+	if file == "" {
+		return printers[format](c.stdout, snapshot, nil)
+	}
+
+	// The snapshot is written to a temporary file first, so an existing
+	// snapshot is never left half written.
+	tmp, err := os.CreateTemp(filepath.Dir(file), filepath.Base(file)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("could not write the snapshot: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := printers[format](tmp, snapshot, nil); err != nil {
+		tmp.Close()
+		return fmt.Errorf("could not write the snapshot: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("could not write the snapshot: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), file); err != nil {
+		return fmt.Errorf("could not write the snapshot: %w", err)
+	}
+
+	return nil
+}
+
+func accountRestore(ctx context.Context, c *cli, file string, dryRun bool) error {
+	snapshot, err := readSnapshot(file)
+	if err != nil {
+		return err
+	}
+
+	operations, responseResult, err := backup.Restore(ctx, c.client, snapshot, &backup.RestoreOpts{DryRun: dryRun})
+	if printErr := c.printOperations(operations); printErr != nil && err == nil {
+		err = printErr
+	}
+	if err != nil {
+		return checkResponse(responseResult, fmt.Errorf("could not restore the snapshot: %w", err))
+	}
+
+	return nil
+}
+
+// printOperations prints operations of a restore.
+func (c *cli) printOperations(operations []*backup.Operation) error {
+	if operations == nil {
+		operations = []*backup.Operation{}
+	}
+
+	t := &table{headers: []string{"ACTION", "L7_RESOURCE_NAME", "L7_RESOURCE_ID", "DETAILS"}}
+	for _, operation := range operations {
+		id := ""
+		if operation.L7ResourceID != 0 {
+			id = strconv.FormatInt(operation.L7ResourceID, 10)
+		}
+		t.rows = append(t.rows, []string{operation.Action, operation.L7ResourceName, id, operation.String()})
+	}
+
+	return c.print(operations, t)
+}
+
+// readSnapshot reads a snapshot file, files with the .yaml or .yml extension
+// are YAML and other files JSON.
+func readSnapshot(file string) (*backup.Snapshot, error) {
+	body, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("could not read the snapshot: %w", err)
+	}
+
+	if ext := filepath.Ext(file); ext == ".yaml" || ext == ".yml" {
+		body, err = yamlToJSON(body)
+		if err != nil {
+			return nil, fmt.Errorf("could not read the snapshot: %w", err)
+		}
+	}
+
+	snapshot := &backup.Snapshot{}
+	if err := json.Unmarshal(body, snapshot); err != nil {
+		return nil, fmt.Errorf("could not read the snapshot: %w", err)
+	}
+
+	return snapshot, nil
+}
+
+// yamlToJSON converts a YAML document into JSON, so it is decoded with the JSON
+// field names of API objects.
+func yamlToJSON(body []byte) ([]byte, error) {
+	var generic any
+	if err := yaml.Unmarshal(body, &generic); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(jsonValue(generic))
+}
+
+// jsonValue converts YAML mappings into JSON objects.
+func jsonValue(value any) any {
+	switch value := value.(type) {
+	case map[any]any:
+		object := make(map[string]any, len(value))
+		for key, element := range value {
+			object[fmt.Sprint(key)] = jsonValue(element)
+		}
+		return object
+	case []any:
+		for i, element := range value {
+			value[i] = jsonValue(element)
+		}
+		return value
+	}
+
+	return value
+}
@@ -0,0 +1,305 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	l7origin "terraform-provider-servicepipe/internal/pkg/sdkv1/l7origin"
+	l7resource "terraform-provider-servicepipe/internal/pkg/sdkv1/l7resource"
+)
+
+// l7resourceType is the Terraform type of generated resources.
+const l7resourceType = "servicepipe_l7resource"
+
+// geoipModeNames maps the API GeoIP modes to geoip_mode values.
+var geoipModeNames = map[int]string{
+	l7resource.GeoipModeOff:   "off",
+	l7resource.GeoipModeAllow: "allow",
+	l7resource.GeoipModeDeny:  "deny",
+}
+
+// resourceGenerate writes servicepipe_l7resource blocks and import blocks of
+// all domains of the account, so existing domains can be adopted with
+// Terraform 1.5 or later.
+func resourceGenerate(ctx context.Context, c *cli, sslDir string) error {
+	items, responseResult, err := l7resource.ListAll(ctx, c.client, nil)
+	if err != nil {
+		return checkResponse(responseResult, fmt.Errorf("could not list l7 resources: %w", err))
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].L7ResourceName != items[j].L7ResourceName {
+			return items[i].L7ResourceName < items[j].L7ResourceName
+		}
+		return items[i].L7ResourceID < items[j].L7ResourceID
+	})
+
+	addresses := make(map[string]bool, len(items))
+	for i, item := range items {
+		origins, responseResult, err := l7origin.ListAll(ctx, c.client, &l7origin.ListOpts{L7ResourceID: item.L7ResourceID})
+		if err != nil {
+			return checkResponse(responseResult, fmt.Errorf("could not list origins of l7 resource %d: %w", item.L7ResourceID, err))
+		}
+
+		name := resourceAddress(item, addresses)
+		if i > 0 {
+			fmt.Fprintln(c.stdout)
+		}
+		if err := writeL7resource(c.stdout, name, item, origins, sslDir); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// resourceAddress returns the resource name derived from the domain name. The
+// identifier of the domain is appended when two names map to the same address.
+func resourceAddress(item *l7resource.Item, used map[string]bool) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(item.L7ResourceName) {
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			b.WriteRune(r)
+		} else {
+			b.WriteRune('_')
+		}
+	}
+
+	name := b.String()
+	if name == "" || !unicode.IsLetter(rune(name[0])) {
+		name = "domain_" + name
+	}
+	if used[name] {
+		name += "_" + strconv.FormatInt(item.L7ResourceID, 10)
+	}
+	used[name] = true
+
+	return name
+}
+
+// writeL7resource writes the import block and the resource block of a domain.
+// Settings equal to the resource defaults are omitted.
+func writeL7resource(w io.Writer, name string, item *l7resource.Item, origins []*l7origin.Item, sslDir string) error {
+	body := &hclBody{}
+	body.attr("l7_resource_name", hclString(item.L7ResourceName))
+	body.boolAttr("l7_resource_is_active", item.L7ResourceIsActive, true)
+	body.boolAttr("l7_protection_disable", item.L7ProtectionDisable, false)
+	body.boolAttr("use_custom_ssl", item.UseCustomSsl, false)
+	body.boolAttr("use_letsencrypt_ssl", item.UseLetsencryptSsl, false)
+	body.boolAttr("force_ssl", item.Forcessl, false)
+	body.boolAttr("service_http2", item.ServiceHTTP2, false)
+	if item.GeoipMode != l7resource.GeoipModeOff {
+		mode, ok := geoipModeNames[item.GeoipMode]
+		if !ok {
+			return fmt.Errorf("l7 resource %d has the unknown GeoIP mode %d", item.L7ResourceID, item.GeoipMode)
+		}
+		body.attr("geoip_mode", hclString(mode))
+	}
+	if codes := geoipCodes(item.GeoipList); len(codes) > 0 {
+		body.attr("geoip_list", hclStringList(codes))
+	}
+	body.boolAttr("global_whitelist_active", item.GlobalWhitelistActive, true)
+	body.boolAttr("http_2_https", item.HTTP2https, false)
+	body.boolAttr("https_2_http", item.HTTPS2http, false)
+	body.boolAttr("www_redir", item.Wwwredir, false)
+	body.boolAttr("cdn", item.Cdn, false)
+	if item.CdnHost != "" {
+		body.attr("cdn_host", hclString(item.CdnHost))
+	}
+	if item.CdnProxyHost != "" {
+		body.attr("cdn_proxy_host", hclString(item.CdnProxyHost))
+	}
+
+	if item.UseCustomSsl != 0 {
+		body.group()
+		body.comment("The API doesn't return SSL material, put the key and the certificate of the domain into these files.")
+		body.attr("custom_ssl_key", hclFile(sslDir+"/"+name+".key"))
+		body.attr("custom_ssl_crt", hclFile(sslDir+"/"+name+".crt"))
+	}
+
+	sorted := make([]*l7origin.Item, len(origins))
+	copy(sorted, origins)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].ID < sorted[j].ID
+	})
+
+	body.group()
+	if len(sorted) == 0 {
+		body.comment("The domain has no origins, at least one origin is required.")
+	}
+	elements := make([]*hclBody, 0, len(sorted))
+	for _, origin := range sorted {
+		element := &hclBody{}
+		element.attr("ip", hclString(origin.IP))
+		element.attr("mode", hclString(origin.Mode))
+		element.attr("weight", strconv.FormatInt(origin.Weight, 10))
+		elements = append(elements, element)
+	}
+	body.objectList("origins", elements)
+
+	_, err := fmt.Fprintf(w, "import {\n  to = %s.%s\n  id = %s\n}\n\nresource %s %s {\n%s}\n",
+		l7resourceType, name, hclString(strconv.FormatInt(item.L7ResourceID, 10)),
+		hclString(l7resourceType), hclString(name), body.render("  "))
+
+	return err
+}
+
+// geoipCodes returns sorted unique country codes of the API country list.
+func geoipCodes(list string) []string {
+	seen := make(map[string]bool)
+	var codes []string
+	for _, code := range strings.FieldsFunc(list, func(r rune) bool {
+		return r == ',' || r == ';' || unicode.IsSpace(r)
+	}) {
+		code = strings.ToUpper(code)
+		if !seen[code] {
+			seen[code] = true
+			codes = append(codes, code)
+		}
+	}
+	sort.Strings(codes)
+
+	return codes
+}
+
+// hclBody is the body of a block or an object, written the way terraform fmt
+// formats it.
+type hclBody struct {
+	// groups contain lines separated by blank lines, equal signs of
+	// attributes are aligned within a group.
+	groups [][]hclLine
+}
+
+// hclLine is an attribute, a comment when name is empty, or an attribute with
+// a list of objects when list is set.
+type hclLine struct {
+	name    string
+	value   string
+	list    bool
+	objects []*hclBody
+}
+
+func (b *hclBody) add(line hclLine) {
+	if len(b.groups) == 0 {
+		b.group()
+	}
+	b.groups[len(b.groups)-1] = append(b.groups[len(b.groups)-1], line)
+}
+
+// group starts a new group of lines.
+func (b *hclBody) group() {
+	if len(b.groups) > 0 && len(b.groups[len(b.groups)-1]) == 0 {
+		return
+	}
+	b.groups = append(b.groups, nil)
+}
+
+func (b *hclBody) attr(name, value string) {
+	b.add(hclLine{name: name, value: value})
+}
+
+// boolAttr adds a toggle unless it is equal to the default.
+func (b *hclBody) boolAttr(name string, value int, defaultValue bool) {
+	if (value != 0) != defaultValue {
+		b.attr(name, strconv.FormatBool(value != 0))
+	}
+}
+
+func (b *hclBody) comment(text string) {
+	b.add(hclLine{value: "# " + text})
+}
+
+func (b *hclBody) objectList(name string, objects []*hclBody) {
+	b.add(hclLine{name: name, list: true, objects: objects})
+}
+
+func (b *hclBody) render(indent string) string {
+	var out strings.Builder
+	for i, group := range b.groups {
+		if len(group) == 0 {
+			continue
+		}
+		if i > 0 && out.Len() > 0 {
+			out.WriteString("\n")
+		}
+
+		width := 0
+		for _, line := range group {
+			if line.name != "" && !line.list && len(line.name) > width {
+				width = len(line.name)
+			}
+		}
+
+		for _, line := range group {
+			switch {
+			case line.name == "":
+				fmt.Fprintf(&out, "%s%s\n", indent, line.value)
+			case line.list && len(line.objects) == 0:
+				fmt.Fprintf(&out, "%s%s = []\n", indent, line.name)
+			case line.list:
+				fmt.Fprintf(&out, "%s%s = [\n", indent, line.name)
+				for _, object := range line.objects {
+					fmt.Fprintf(&out, "%s  {\n%s%s  },\n", indent, object.render(indent+"    "), indent)
+				}
+				fmt.Fprintf(&out, "%s]\n", indent)
+			default:
+				fmt.Fprintf(&out, "%s%-*s = %s\n", indent, width, line.name, line.value)
+			}
+		}
+	}
+
+	return out.String()
+}
+
+// hclString returns the HCL quoted string of the value, template sequences
+// are escaped so the value is used as is.
+func hclString(value string) string {
+	return `"` + hclEscape(value) + `"`
+}
+
+// hclStringList returns the HCL list of quoted strings.
+func hclStringList(values []string) string {
+	quoted := make([]string, 0, len(values))
+	for _, value := range values {
+		quoted = append(quoted, hclString(value))
+	}
+
+	return "[" + strings.Join(quoted, ", ") + "]"
+}
+
+// hclFile returns the expression reading the file relative to the module.
+func hclFile(name string) string {
+	return `file("${path.module}/` + hclEscape(name) + `")`
+}
+
+func hclEscape(value string) string {
+	var b strings.Builder
+	for i, r := range value {
+		switch {
+		case r == '\\':
+			b.WriteString(`\\`)
+		case r == '"':
+			b.WriteString(`\"`)
+		case r == '\n':
+			b.WriteString(`\n`)
+		case r == '\r':
+			b.WriteString(`\r`)
+		case r == '\t':
+			b.WriteString(`\t`)
+		case (r == '$' || r == '%') && strings.HasPrefix(value[i+1:], "{"):
+			b.WriteRune(r)
+			b.WriteRune(r)
+		case unicode.IsControl(r):
+			fmt.Fprintf(&b, `\u%04x`, r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+
+	return b.String()
+}
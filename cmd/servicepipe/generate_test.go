@@ -0,0 +1,190 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	l7origin "terraform-provider-servicepipe/internal/pkg/sdkv1/l7origin"
+	l7resource "terraform-provider-servicepipe/internal/pkg/sdkv1/l7resource"
+)
+
+func TestHCLEscape(t *testing.T) {
+	tests := map[string]struct {
+		value string
+		want  string
+	}{
+		"plain":                  {value: "example.com", want: "example.com"},
+		"interpolation":          {value: "${var.name}", want: "$${var.name}"},
+		"directive":              {value: "%{if true}", want: "%%{if true}"},
+		"dollar without a brace": {value: "$5 and 100%", want: "$5 and 100%"},
+		"brace without a dollar": {value: "{}", want: "{}"},
+		"quotes":                 {value: `say "hi"`, want: `say \"hi\"`},
+		"backslashes":            {value: `C:\ssl\key`, want: `C:\\ssl\\key`},
+		"newlines":               {value: "a\nb\r\n", want: `a\nb\r\n`},
+		"tabs":                   {value: "a\tb", want: `a\tb`},
+		"control characters":     {value: "a\x01b", want: `a\u0001b`},
+		"unicode":                {value: "пример.рф", want: "пример.рф"},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := hclEscape(test.value); got != test.want {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestResourceAddress(t *testing.T) {
+	tests := map[string]struct {
+		names []string
+		want  []string
+	}{
+		"domain": {
+			names: []string{"example.com"},
+			want:  []string{"example_com"},
+		},
+		"upper case": {
+			names: []string{"WWW.Example.COM"},
+			want:  []string{"www_example_com"},
+		},
+		"non-identifier characters": {
+			names: []string{"my-site.example.com:8080"},
+			want:  []string{"my_site_example_com_8080"},
+		},
+		"leading digit": {
+			names: []string{"1.example.com"},
+			want:  []string{"domain_1_example_com"},
+		},
+		"punycode": {
+			names: []string{"xn--e1afmkfd.xn--p1ai"},
+			want:  []string{"xn__e1afmkfd_xn__p1ai"},
+		},
+		"unicode": {
+			names: []string{"пример.рф"},
+			want:  []string{"domain__________"},
+		},
+		"empty": {
+			names: []string{""},
+			want:  []string{"domain_"},
+		},
+		"duplicate addresses": {
+			names: []string{"example.com", "example-com", "EXAMPLE.COM"},
+			want:  []string{"example_com", "example_com_2", "example_com_3"},
+		},
+		"unicode duplicates": {
+			names: []string{"пример.рф", "тестер.рф"},
+			want:  []string{"domain__________", "domain___________2"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			used := make(map[string]bool)
+			for i, domain := range test.names {
+				item := &l7resource.Item{L7ResourceID: int64(i + 1), L7ResourceName: domain}
+				if got := resourceAddress(item, used); got != test.want[i] {
+					t.Errorf("got address %q of %q, want %q", got, domain, test.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestWriteL7resource(t *testing.T) {
+	tests := map[string]struct {
+		item    *l7resource.Item
+		origins []*l7origin.Item
+		want    string
+	}{
+		"domain with origins": {
+			item: &l7resource.Item{
+				L7ResourceID:          42,
+				L7ResourceName:        "example.com",
+				L7ResourceIsActive:    1,
+				UseCustomSsl:          1,
+				ServiceHTTP2:          1,
+				GeoipMode:             l7resource.GeoipModeAllow,
+				GeoipList:             "ru; by,ru",
+				GlobalWhitelistActive: 1,
+				CdnHost:               "${cdn}.example.com",
+			},
+			origins: []*l7origin.Item{
+				{ID: 2, IP: "192.0.2.2", Mode: l7origin.ModeBackup, Weight: 1},
+				{ID: 1, IP: "192.0.2.1", Mode: l7origin.ModePrimary, Weight: 100},
+			},
+			want: `import {
+  to = servicepipe_l7resource.example_com
+  id = "42"
+}
+
+resource "servicepipe_l7resource" "example_com" {
+  l7_resource_name = "example.com"
+  use_custom_ssl   = true
+  service_http2    = true
+  geoip_mode       = "allow"
+  geoip_list       = ["BY", "RU"]
+  cdn_host         = "$${cdn}.example.com"
+
+  # The API doesn't return SSL material, put the key and the certificate of the domain into these files.
+  custom_ssl_key = file("${path.module}/ssl/example_com.key")
+  custom_ssl_crt = file("${path.module}/ssl/example_com.crt")
+
+  origins = [
+    {
+      ip     = "192.0.2.1"
+      mode   = "primary"
+      weight = 100
+    },
+    {
+      ip     = "192.0.2.2"
+      mode   = "backup"
+      weight = 1
+    },
+  ]
+}
+`,
+		},
+		"domain without origins": {
+			item: &l7resource.Item{
+				L7ResourceID:          7,
+				L7ResourceName:        "example.org",
+				GlobalWhitelistActive: 1,
+			},
+			want: `import {
+  to = servicepipe_l7resource.example_org
+  id = "7"
+}
+
+resource "servicepipe_l7resource" "example_org" {
+  l7_resource_name      = "example.org"
+  l7_resource_is_active = false
+
+  # The domain has no origins, at least one origin is required.
+  origins = []
+}
+`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			name := resourceAddress(test.item, map[string]bool{})
+
+			var out strings.Builder
+			if err := writeL7resource(&out, name, test.item, test.origins, "ssl"); err != nil {
+				t.Fatal(err)
+			}
+			if got := out.String(); got != test.want {
+				t.Errorf("got\n%s\nwant\n%s", got, test.want)
+			}
+		})
+	}
+
+	t.Run("unknown GeoIP mode", func(t *testing.T) {
+		item := &l7resource.Item{L7ResourceID: 1, L7ResourceName: "example.com", GeoipMode: 9}
+		if err := writeL7resource(&strings.Builder{}, "example_com", item, nil, "ssl"); err == nil {
+			t.Error("got no error, want one")
+		}
+	})
+}
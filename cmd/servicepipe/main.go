@@ -0,0 +1,259 @@
+// Command servicepipe manages Servicepipe l7 resources and origins from the
+// command line with the same API client the provider uses.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+
+	v1 "terraform-provider-servicepipe/internal/pkg/sdkv1"
+	l7resource "terraform-provider-servicepipe/internal/pkg/sdkv1/l7resource"
+	"terraform-provider-servicepipe/internal/pkg/tracing"
+)
+
+const (
+	// tokenEnv is the environment variable with the API token.
+	tokenEnv = "SERVICEPIPE_TOKEN"
+
+	// endpointEnv is the environment variable with the API endpoint.
+	endpointEnv = "SERVICEPIPE_ENDPOINT"
+)
+
+// Exit codes of the command.
+const (
+	exitOK = iota
+	exitError
+	exitUsage
+	exitUnauthorized
+	exitNotFound
+	exitConflict
+	exitInvalidRequest
+	exitServerError
+)
+
+// errUsage is returned when the command line is invalid.
+var errUsage = errors.New("invalid usage")
+
+// command is a single subcommand. setup registers flags of the subcommand and
+// returns the function that runs it with the positional arguments.
+type command struct {
+	usage string
+	args  int
+
+	// noOutput is set when the output format of the command is fixed.
+	noOutput bool
+
+	setup func(flags *flag.FlagSet) func(ctx context.Context, cli *cli, args []string) error
+}
+
+// commands maps command groups to their subcommands.
+var commands = map[string]map[string]command{
+	"resource": resourceCommands,
+	"origin":   originCommands,
+	"account":  accountCommands,
+}
+
+// cli contains the configuration shared by all subcommands.
+type cli struct {
+	client *v1.Client
+	output string
+	stdout io.Writer
+}
+
+const usage = `Usage: servicepipe <command> <subcommand> [flags] [args]
+
+Commands:
+  resource list
+  resource get <l7-resource-id>
+  resource create -name <name> -origin <ip> [-www-redir]
+  resource update <l7-resource-id> -set <field>=<value> [-set ...]
+  resource delete <l7-resource-id>
+  resource generate [-ssl-dir <dir>]
+  origin list <l7-resource-id>
+  origin add <l7-resource-id> -ip <ip> [-weight <weight>] [-mode primary|backup]
+  origin remove <l7-resource-id> <origin-id>
+  origin set-weight <l7-resource-id> <origin-id> <weight>
+  account backup [-format json|yaml] [-file <path>]
+  account restore <snapshot-file> [-dry-run]
+
+Flags of every subcommand:
+  -output table|json|yaml  output format, table by default, not used by resource generate and account backup
+  -endpoint <url>          API endpoint, SERVICEPIPE_ENDPOINT or the default endpoint when empty
+
+The API token is read from SERVICEPIPE_TOKEN.
+
+Exit codes:
+  0 success, 1 unexpected error, 2 invalid usage, 3 unauthorized, 4 not found,
+  5 conflict, 6 invalid request, 7 server error
+`
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+
+	shutdownTracing, err := tracing.Setup(ctx, "servicepipe", "dev")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "servicepipe: warning: could not set up tracing, continuing without it: %s\n", err)
+		shutdownTracing = func(context.Context) error { return nil }
+	}
+
+	code := run(ctx, os.Args[1:], os.Stdout, os.Stderr)
+	if err := shutdownTracing(context.Background()); err != nil {
+		fmt.Fprintf(os.Stderr, "servicepipe: could not flush traces: %s\n", err)
+	}
+	stop()
+	os.Exit(code)
+}
+
+// run executes the command line and returns the exit code.
+func run(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	if len(args) < 2 {
+		fmt.Fprint(stderr, usage)
+		return exitUsage
+	}
+
+	cmd, ok := commands[args[0]][args[1]]
+	if !ok {
+		fmt.Fprintf(stderr, "unknown command %q\n\n%s", args[0]+" "+args[1], usage)
+		return exitUsage
+	}
+
+	flags := flag.NewFlagSet(args[0]+" "+args[1], flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintf(stderr, "Usage: servicepipe %s %s\n", args[0], cmd.usage)
+		flags.PrintDefaults()
+	}
+
+	c := &cli{stdout: stdout, output: outputTable}
+	if !cmd.noOutput {
+		flags.StringVar(&c.output, "output", outputTable, "output format: table, json or yaml")
+	}
+	endpoint := flags.String("endpoint", os.Getenv(endpointEnv), "API endpoint")
+	runCommand := cmd.setup(flags)
+
+	err := func() error {
+		positional, err := parseFlags(flags, args[2:])
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		if err != nil {
+			return fmt.Errorf("%w: %s", errUsage, err)
+		}
+		if len(positional) != cmd.args {
+			flags.Usage()
+			return fmt.Errorf("%w: expected %d arguments, got %d", errUsage, cmd.args, len(positional))
+		}
+		if _, ok := printers[c.output]; !ok {
+			return fmt.Errorf("%w: unknown output format %q", errUsage, c.output)
+		}
+
+		c.client, err = newClient(*endpoint)
+		if err != nil {
+			return err
+		}
+
+		return runCommand(ctx, c, positional)
+	}()
+	if err == nil {
+		return exitOK
+	}
+	if errors.Is(err, flag.ErrHelp) {
+		return exitUsage
+	}
+
+	fmt.Fprintf(stderr, "servicepipe: %s\n", err)
+
+	return exitCode(err)
+}
+
+// parseFlags parses flags that may follow positional arguments and returns the
+// positional arguments.
+func parseFlags(flags *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := flags.Parse(args); err != nil {
+			return nil, err
+		}
+
+		args = flags.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// newClient creates the API client from the environment.
+func newClient(endpoint string) (*v1.Client, error) {
+	token := os.Getenv(tokenEnv)
+	if token == "" {
+		return nil, fmt.Errorf("%w: environment variable %s is not set", errUsage, tokenEnv)
+	}
+
+	if endpoint == "" {
+		return v1.NewClientV1WithDefaultEndpoint(token), nil
+	}
+
+	return v1.NewClientV1(token, endpoint), nil
+}
+
+// apiError is an error of an API request with the response status code.
+type apiError struct {
+	statusCode int
+	err        error
+}
+
+func (e *apiError) Error() string {
+	return e.err.Error()
+}
+
+func (e *apiError) Unwrap() error {
+	return e.err
+}
+
+// checkResponse adds the response status code to the error of an API request.
+func checkResponse(responseResult *v1.ResponseResult, err error) error {
+	if err == nil || responseResult == nil || responseResult.Response == nil {
+		return err
+	}
+
+	return &apiError{statusCode: responseResult.StatusCode, err: err}
+}
+
+// exitCode maps the error to the exit code of the command.
+func exitCode(err error) int {
+	if errors.Is(err, errUsage) || errors.Is(err, flag.ErrHelp) {
+		return exitUsage
+	}
+	if errors.Is(err, l7resource.ErrConcurrentModification) {
+		return exitConflict
+	}
+
+	var apiErr *apiError
+	if !errors.As(err, &apiErr) {
+		return exitError
+	}
+
+	switch {
+	case apiErr.statusCode == http.StatusUnauthorized, apiErr.statusCode == http.StatusForbidden:
+		return exitUnauthorized
+	case apiErr.statusCode == http.StatusNotFound:
+		return exitNotFound
+	case apiErr.statusCode == http.StatusConflict:
+		return exitConflict
+	case apiErr.statusCode >= http.StatusInternalServerError:
+		return exitServerError
+	case apiErr.statusCode >= http.StatusBadRequest:
+		return exitInvalidRequest
+	}
+
+	return exitError
+}
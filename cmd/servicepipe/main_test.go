@@ -0,0 +1,95 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"testing"
+
+	l7resource "terraform-provider-servicepipe/internal/pkg/sdkv1/l7resource"
+)
+
+func TestExitCode(t *testing.T) {
+	apiErr := func(statusCode int) error {
+		return &apiError{statusCode: statusCode, err: errors.New("sp-go: got an error")}
+	}
+
+	tests := map[string]struct {
+		err  error
+		want int
+	}{
+		"unexpected":               {err: errors.New("could not read the snapshot"), want: exitError},
+		"usage":                    {err: fmt.Errorf("%w: -ip is required", errUsage), want: exitUsage},
+		"help":                     {err: flag.ErrHelp, want: exitUsage},
+		"concurrent modification":  {err: fmt.Errorf("could not update l7 resource 1: %w", l7resource.ErrConcurrentModification), want: exitConflict},
+		"unauthorized":             {err: apiErr(http.StatusUnauthorized), want: exitUnauthorized},
+		"forbidden":                {err: apiErr(http.StatusForbidden), want: exitUnauthorized},
+		"not found":                {err: apiErr(http.StatusNotFound), want: exitNotFound},
+		"conflict":                 {err: apiErr(http.StatusConflict), want: exitConflict},
+		"invalid request":          {err: apiErr(http.StatusUnprocessableEntity), want: exitInvalidRequest},
+		"bad request":              {err: apiErr(http.StatusBadRequest), want: exitInvalidRequest},
+		"server error":             {err: apiErr(http.StatusBadGateway), want: exitServerError},
+		"status without an error":  {err: apiErr(http.StatusOK), want: exitError},
+		"wrapped API error":        {err: fmt.Errorf("could not restore the snapshot: %w", apiErr(http.StatusNotFound)), want: exitNotFound},
+		"API error wrapping usage": {err: &apiError{statusCode: http.StatusNotFound, err: errUsage}, want: exitUsage},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := exitCode(test.err); got != test.want {
+				t.Errorf("got exit code %d, want %d", got, test.want)
+			}
+		})
+	}
+}
+
+func TestParseFlags(t *testing.T) {
+	tests := map[string]struct {
+		args     []string
+		want     []string
+		wantIP   string
+		wantErr  bool
+		wantHelp bool
+	}{
+		"no arguments":             {},
+		"positional only":          {args: []string{"1", "2"}, want: []string{"1", "2"}},
+		"flags first":              {args: []string{"-ip", "192.0.2.1", "1"}, want: []string{"1"}, wantIP: "192.0.2.1"},
+		"flags after positional":   {args: []string{"1", "-ip", "192.0.2.1", "2"}, want: []string{"1", "2"}, wantIP: "192.0.2.1"},
+		"terminator":               {args: []string{"1", "--", "-ip"}, want: []string{"1", "-ip"}},
+		"unknown flag":             {args: []string{"1", "-unknown"}, wantErr: true},
+		"missing flag value":       {args: []string{"1", "-ip"}, wantErr: true},
+		"invalid flag value":       {args: []string{"-weight", "heavy"}, wantErr: true},
+		"help":                     {args: []string{"1", "-h"}, wantErr: true, wantHelp: true},
+		"invalid value after args": {args: []string{"1", "2", "-weight=x"}, wantErr: true},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			flags := flag.NewFlagSet("origin add", flag.ContinueOnError)
+			flags.SetOutput(io.Discard)
+			ip := flags.String("ip", "", "")
+			flags.Int64("weight", 100, "")
+
+			got, err := parseFlags(flags, test.args)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("got error %v, want error %t", err, test.wantErr)
+			}
+			if errors.Is(err, flag.ErrHelp) != test.wantHelp {
+				t.Errorf("got error %v, want help %t", err, test.wantHelp)
+			}
+			if test.wantErr {
+				return
+			}
+
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got arguments %q, want %q", got, test.want)
+			}
+			if *ip != test.wantIP {
+				t.Errorf("got ip %q, want %q", *ip, test.wantIP)
+			}
+		})
+	}
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strconv"
+
+	l7origin "terraform-provider-servicepipe/internal/pkg/sdkv1/l7origin"
+)
+
+// originCommands contains subcommands of the origin command.
+var originCommands = map[string]command{
+	"list": {
+		usage: "list <l7-resource-id>",
+		args:  1,
+		setup: func(_ *flag.FlagSet) func(context.Context, *cli, []string) error {
+			return originList
+		},
+	},
+	"add": {
+		usage: "add <l7-resource-id> -ip <ip> [-weight <weight>] [-mode primary|backup]",
+		args:  1,
+		setup: func(flags *flag.FlagSet) func(context.Context, *cli, []string) error {
+			opts := &l7origin.CreateOpts{}
+			flags.StringVar(&opts.IP, "ip", "", "IP address of the origin")
+			flags.Int64Var(&opts.Weight, "weight", l7origin.MaxWeight, "weight of the origin")
+			flags.StringVar(&opts.Mode, "mode", l7origin.ModePrimary, "mode of the origin: primary or backup")
+
+			return func(ctx context.Context, c *cli, args []string) error {
+				return originAdd(ctx, c, args, opts)
+			}
+		},
+	},
+	"remove": {
+		usage: "remove <l7-resource-id> <origin-id>",
+		args:  2,
+		setup: func(_ *flag.FlagSet) func(context.Context, *cli, []string) error {
+			return originRemove
+		},
+	},
+	"set-weight": {
+		usage: "set-weight <l7-resource-id> <origin-id> <weight>",
+		args:  3,
+		setup: func(_ *flag.FlagSet) func(context.Context, *cli, []string) error {
+			return originSetWeight
+		},
+	},
+}
+
+func originList(ctx context.Context, c *cli, args []string) error {
+	l7ResourceID, err := parseID("l7-resource-id", args[0])
+	if err != nil {
+		return err
+	}
+
+	items, responseResult, err := l7origin.ListAll(ctx, c.client, &l7origin.ListOpts{L7ResourceID: l7ResourceID})
+	if err != nil {
+		return checkResponse(responseResult, fmt.Errorf("could not list origins of l7 resource %d: %w", l7ResourceID, err))
+	}
+
+	t := originTable()
+	for _, item := range items {
+		t.rows = append(t.rows, originRow(item))
+	}
+
+	return c.print(items, t)
+}
+
+func originAdd(ctx context.Context, c *cli, args []string, opts *l7origin.CreateOpts) error {
+	l7ResourceID, err := parseID("l7-resource-id", args[0])
+	if err != nil {
+		return err
+	}
+
+	if opts.IP == "" {
+		return fmt.Errorf("%w: -ip is required", errUsage)
+	}
+	if err := checkOrigin(opts.Weight, opts.Mode); err != nil {
+		return err
+	}
+	opts.L7ResourceID = l7ResourceID
+
+	response, responseResult, err := l7origin.Create(ctx, c.client, opts)
+	if err != nil {
+		return checkResponse(responseResult, fmt.Errorf("could not add origin %s to l7 resource %d: %w", opts.IP, l7ResourceID, err))
+	}
+
+	return c.printOrigin(&response.Data.Result)
+}
+
+func originRemove(ctx context.Context, c *cli, args []string) error {
+	l7ResourceID, err := parseID("l7-resource-id", args[0])
+	if err != nil {
+		return err
+	}
+	id, err := parseID("origin-id", args[1])
+	if err != nil {
+		return err
+	}
+
+	response, responseResult, err := l7origin.Delete(ctx, c.client, &l7origin.DeleteOpts{L7ResourceID: l7ResourceID, ID: id})
+	if err != nil {
+		return checkResponse(responseResult, fmt.Errorf("could not remove origin %d of l7 resource %d: %w", id, l7ResourceID, err))
+	}
+
+	return c.print(response.Data, &table{
+		headers: []string{"L7_RESOURCE_ID", "ID", "RESULT"},
+		rows:    [][]string{{strconv.FormatInt(l7ResourceID, 10), strconv.FormatInt(id, 10), response.Data.Result}},
+	})
+}
+
+func originSetWeight(ctx context.Context, c *cli, args []string) error {
+	l7ResourceID, err := parseID("l7-resource-id", args[0])
+	if err != nil {
+		return err
+	}
+	id, err := parseID("origin-id", args[1])
+	if err != nil {
+		return err
+	}
+	weight, err := strconv.ParseInt(args[2], 10, 64)
+	if err != nil {
+		return fmt.Errorf("%w: weight must be a number, got %q", errUsage, args[2])
+	}
+
+	current, responseResult, err := l7origin.GetByID(ctx, c.client, int(l7ResourceID), int(id))
+	if err != nil {
+		return checkResponse(responseResult, fmt.Errorf("could not get origin %d of l7 resource %d: %w", id, l7ResourceID, err))
+	}
+
+	// The API does not return the resource identifier of origins.
+	item := current.Data.Result
+	item.L7ResourceID = l7ResourceID
+	if err := checkOrigin(weight, item.Mode); err != nil {
+		return err
+	}
+	previous := item
+	item.Weight = weight
+
+	response, responseResult, err := l7origin.UpdateFrom(ctx, c.client, &previous, &item)
+	if err != nil {
+		return checkResponse(responseResult, fmt.Errorf("could not update origin %d of l7 resource %d: %w", id, l7ResourceID, err))
+	}
+
+	return c.printOrigin(&response.Data.Result)
+}
+
+// checkOrigin validates the origin weight and mode before they are sent.
+func checkOrigin(weight int64, mode string) error {
+	if weight < l7origin.MinWeight || weight > l7origin.MaxWeight {
+		return fmt.Errorf("%w: weight must be between %d and %d, got %d", errUsage, l7origin.MinWeight, l7origin.MaxWeight, weight)
+	}
+	if mode != l7origin.ModePrimary && mode != l7origin.ModeBackup {
+		return fmt.Errorf("%w: mode must be %s or %s, got %q", errUsage, l7origin.ModePrimary, l7origin.ModeBackup, mode)
+	}
+
+	return nil
+}
+
+// printOrigin prints a single origin.
+func (c *cli) printOrigin(item *l7origin.Item) error {
+	t := originTable()
+	t.rows = append(t.rows, originRow(item))
+
+	return c.print(item, t)
+}
+
+func originTable() *table {
+	return &table{headers: []string{"L7_RESOURCE_ID", "ID", "IP", "MODE", "WEIGHT", "MODIFIED_AT"}}
+}
+
+func originRow(item *l7origin.Item) []string {
+	return []string{
+		strconv.FormatInt(item.L7ResourceID, 10),
+		strconv.FormatInt(item.ID, 10),
+		item.IP,
+		item.Mode,
+		strconv.FormatInt(item.Weight, 10),
+		strconv.FormatInt(item.ModifiedAt, 10),
+	}
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	l7origin "terraform-provider-servicepipe/internal/pkg/sdkv1/l7origin"
+)
+
+// newOriginServer returns a server of origin 2 of l7 resource 1 and the
+// origins sent by PUT requests.
+func newOriginServer(t *testing.T) (*httptest.Server, *[]l7origin.Item) {
+	t.Helper()
+
+	var updates []l7origin.Item
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"error":"unauthorized"}`))
+			return
+		}
+
+		result := l7origin.Data{}
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/l7/origin/1/2":
+			// The API does not return the resource identifier of origins.
+			result.Data.Result = l7origin.Item{ID: 2, IP: "192.0.2.2", Mode: l7origin.ModeBackup, Weight: 100, ModifiedAt: 1000}
+		case r.Method == http.MethodPut && r.URL.Path == "/l7/origin":
+			if err := json.NewDecoder(r.Body).Decode(&result.Data.Result); err != nil {
+				t.Error(err)
+			}
+			updates = append(updates, result.Data.Result)
+			result.Data.Result.ModifiedAt = 2000
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error":"not found"}`))
+			return
+		}
+
+		if err := json.NewEncoder(w).Encode(result); err != nil {
+			t.Error(err)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return server, &updates
+}
+
+func TestOriginSetWeight(t *testing.T) {
+	tests := map[string]struct {
+		token       string
+		args        []string
+		wantCode    int
+		wantUpdates []l7origin.Item
+		wantStdout  string
+		wantStderr  string
+	}{
+		"weight set": {
+			token:    "token",
+			args:     []string{"1", "2", "50", "-output", "json"},
+			wantCode: exitOK,
+			wantUpdates: []l7origin.Item{
+				{L7ResourceID: 1, ID: 2, IP: "192.0.2.2", Mode: l7origin.ModeBackup, Weight: 50, ModifiedAt: 1000},
+			},
+			wantStdout: `"weight": 50`,
+		},
+		"table output": {
+			token:    "token",
+			args:     []string{"1", "2", "0"},
+			wantCode: exitOK,
+			wantUpdates: []l7origin.Item{
+				{L7ResourceID: 1, ID: 2, IP: "192.0.2.2", Mode: l7origin.ModeBackup, Weight: 0, ModifiedAt: 1000},
+			},
+			wantStdout: "1               2   192.0.2.2  backup  0       2000",
+		},
+		"weight out of range": {
+			token:      "token",
+			args:       []string{"1", "2", "101"},
+			wantCode:   exitUsage,
+			wantStderr: "weight must be between 0 and 100, got 101",
+		},
+		"missing origin": {
+			token:      "token",
+			args:       []string{"1", "3", "50"},
+			wantCode:   exitNotFound,
+			wantStderr: "could not get origin 3 of l7 resource 1",
+		},
+		"unauthorized": {
+			token:      "other",
+			args:       []string{"1", "2", "50"},
+			wantCode:   exitUnauthorized,
+			wantStderr: "could not get origin 2 of l7 resource 1",
+		},
+		"missing token": {
+			args:       []string{"1", "2", "50"},
+			wantCode:   exitUsage,
+			wantStderr: "environment variable SERVICEPIPE_TOKEN is not set",
+		},
+		"missing argument": {
+			token:    "token",
+			args:     []string{"1", "2"},
+			wantCode: exitUsage,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			server, updates := newOriginServer(t)
+			t.Setenv(tokenEnv, test.token)
+
+			args := append([]string{"origin", "set-weight", "-endpoint", server.URL}, test.args...)
+			var stdout, stderr strings.Builder
+			if code := run(context.Background(), args, &stdout, &stderr); code != test.wantCode {
+				t.Errorf("got exit code %d, want %d, stderr %q", code, test.wantCode, stderr.String())
+			}
+
+			if len(*updates) != len(test.wantUpdates) {
+				t.Fatalf("got updates %+v, want %+v", *updates, test.wantUpdates)
+			}
+			for i := range test.wantUpdates {
+				if (*updates)[i] != test.wantUpdates[i] {
+					t.Errorf("got update %+v, want %+v", (*updates)[i], test.wantUpdates[i])
+				}
+			}
+			if !strings.Contains(stdout.String(), test.wantStdout) {
+				t.Errorf("got output %q, want %q", stdout.String(), test.wantStdout)
+			}
+			if !strings.Contains(stderr.String(), test.wantStderr) {
+				t.Errorf("got errors %q, want %q", stderr.String(), test.wantStderr)
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+	outputYAML  = "yaml"
+)
+
+// table is the tabular form of a command result.
+type table struct {
+	headers []string
+	rows    [][]string
+}
+
+// printer writes a command result, value is the API object and t its tabular
+// form.
+type printer func(w io.Writer, value any, t *table) error
+
+// printers maps output formats to their printers.
+var printers = map[string]printer{
+	outputTable: printTable,
+	outputJSON:  printJSON,
+	outputYAML:  printYAML,
+}
+
+// print writes the command result in the configured output format.
+func (c *cli) print(value any, t *table) error {
+	return printers[c.output](c.stdout, value, t)
+}
+
+func printTable(w io.Writer, _ any, t *table) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(t.headers, "\t"))
+	for _, row := range t.rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+
+	return tw.Flush()
+}
+
+func printJSON(w io.Writer, value any, _ *table) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(value)
+}
+
+// printYAML writes the value with the field names of its JSON form, API
+// objects have no yaml tags.
+func printYAML(w io.Writer, value any, _ *table) error {
+	body, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	var generic any
+	if err := yaml.Unmarshal(body, &generic); err != nil {
+		return err
+	}
+
+	body, err = yaml.Marshal(generic)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(body)
+
+	return err
+}
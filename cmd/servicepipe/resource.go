@@ -0,0 +1,256 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+
+	l7resource "terraform-provider-servicepipe/internal/pkg/sdkv1/l7resource"
+)
+
+// resourceCommands contains subcommands of the resource command.
+var resourceCommands = map[string]command{
+	"list": {
+		usage: "list",
+		setup: func(_ *flag.FlagSet) func(context.Context, *cli, []string) error {
+			return resourceList
+		},
+	},
+	"get": {
+		usage: "get <l7-resource-id>",
+		args:  1,
+		setup: func(_ *flag.FlagSet) func(context.Context, *cli, []string) error {
+			return resourceGet
+		},
+	},
+	"create": {
+		usage: "create -name <name> -origin <ip> [-www-redir]",
+		setup: func(flags *flag.FlagSet) func(context.Context, *cli, []string) error {
+			opts := &l7resource.CreateOpts{}
+			flags.StringVar(&opts.L7ResourceName, "name", "", "domain name")
+			flags.StringVar(&opts.OriginData, "origin", "", "IP address of the first origin")
+			wwwRedir := flags.Bool("www-redir", false, "redirect www. to the domain")
+
+			return func(ctx context.Context, c *cli, _ []string) error {
+				if *wwwRedir {
+					opts.Wwwredir = 1
+				}
+
+				return resourceCreate(ctx, c, opts)
+			}
+		},
+	},
+	"update": {
+		usage: "update <l7-resource-id> -set <field>=<value> [-set ...]",
+		args:  1,
+		setup: func(flags *flag.FlagSet) func(context.Context, *cli, []string) error {
+			var settings settingsFlag
+			flags.Var(&settings, "set", "`field=value` to change, field is an API field name such as forcessl or cdnHost")
+			ifModifiedAt := flags.Int("if-modified-at", 0, "fail when the domain modification time is different")
+
+			return func(ctx context.Context, c *cli, args []string) error {
+				return resourceUpdate(ctx, c, args, settings, *ifModifiedAt)
+			}
+		},
+	},
+	"delete": {
+		usage: "delete <l7-resource-id>",
+		args:  1,
+		setup: func(_ *flag.FlagSet) func(context.Context, *cli, []string) error {
+			return resourceDelete
+		},
+	},
+	"generate": {
+		usage:    "generate [-ssl-dir <dir>]",
+		noOutput: true,
+		setup: func(flags *flag.FlagSet) func(context.Context, *cli, []string) error {
+			sslDir := flags.String("ssl-dir", "ssl", "directory, relative to the module, of SSL keys and certificates of domains with use_custom_ssl")
+
+			return func(ctx context.Context, c *cli, _ []string) error {
+				return resourceGenerate(ctx, c, *sslDir)
+			}
+		},
+	},
+}
+
+func resourceList(ctx context.Context, c *cli, _ []string) error {
+	items, responseResult, err := l7resource.ListAll(ctx, c.client, nil)
+	if err != nil {
+		return checkResponse(responseResult, fmt.Errorf("could not list l7 resources: %w", err))
+	}
+
+	t := resourceTable()
+	for _, item := range items {
+		t.rows = append(t.rows, resourceRow(item))
+	}
+
+	return c.print(items, t)
+}
+
+func resourceGet(ctx context.Context, c *cli, args []string) error {
+	l7ResourceID, err := parseID("l7-resource-id", args[0])
+	if err != nil {
+		return err
+	}
+
+	response, responseResult, err := l7resource.GetByID(ctx, c.client, int(l7ResourceID))
+	if err != nil {
+		return checkResponse(responseResult, fmt.Errorf("could not get l7 resource %d: %w", l7ResourceID, err))
+	}
+
+	return c.printResource(&response.Data.Result)
+}
+
+func resourceCreate(ctx context.Context, c *cli, opts *l7resource.CreateOpts) error {
+	if opts.L7ResourceName == "" || opts.OriginData == "" {
+		return fmt.Errorf("%w: -name and -origin are required", errUsage)
+	}
+
+	response, responseResult, err := l7resource.Create(ctx, c.client, opts)
+	if err != nil {
+		return checkResponse(responseResult, fmt.Errorf("could not create l7 resource %s: %w", opts.L7ResourceName, err))
+	}
+
+	return c.printResource(&response.Data.Result)
+}
+
+func resourceUpdate(ctx context.Context, c *cli, args []string, settings settingsFlag, ifModifiedAt int) error {
+	l7ResourceID, err := parseID("l7-resource-id", args[0])
+	if err != nil {
+		return err
+	}
+
+	opts, err := settings.updateOpts(l7ResourceID)
+	if err != nil {
+		return err
+	}
+	opts.IfModifiedAt = ifModifiedAt
+
+	response, responseResult, err := l7resource.Patch(ctx, c.client, opts)
+	if err != nil {
+		return checkResponse(responseResult, fmt.Errorf("could not update l7 resource %d: %w", l7ResourceID, err))
+	}
+
+	return c.printResource(&response.Data.Result)
+}
+
+func resourceDelete(ctx context.Context, c *cli, args []string) error {
+	l7ResourceID, err := parseID("l7-resource-id", args[0])
+	if err != nil {
+		return err
+	}
+
+	response, responseResult, err := l7resource.Delete(ctx, c.client, &l7resource.DeleteOpts{L7ResourceID: int(l7ResourceID)})
+	if err != nil {
+		return checkResponse(responseResult, fmt.Errorf("could not delete l7 resource %d: %w", l7ResourceID, err))
+	}
+
+	return c.print(response.Data, &table{
+		headers: []string{"L7_RESOURCE_ID", "RESULT"},
+		rows:    [][]string{{strconv.FormatInt(l7ResourceID, 10), response.Data.Result}},
+	})
+}
+
+// printResource prints a single domain.
+func (c *cli) printResource(item *l7resource.Item) error {
+	t := resourceTable()
+	t.rows = append(t.rows, resourceRow(item))
+
+	return c.print(item, t)
+}
+
+func resourceTable() *table {
+	return &table{headers: []string{"L7_RESOURCE_ID", "NAME", "ACTIVE", "PROTECTED_IP", "FORCE_SSL", "CDN", "MODIFIED_AT"}}
+}
+
+func resourceRow(item *l7resource.Item) []string {
+	name, err := l7resource.UnicodeName(item.L7ResourceName)
+	if err != nil {
+		name = item.L7ResourceName
+	}
+
+	return []string{
+		strconv.FormatInt(item.L7ResourceID, 10),
+		name,
+		strconv.Itoa(item.L7ResourceIsActive),
+		item.ProtectedIp,
+		strconv.Itoa(item.Forcessl),
+		strconv.Itoa(item.Cdn),
+		strconv.Itoa(item.ModifiedAt),
+	}
+}
+
+// settingsFlag collects field=value pairs of the -set flag.
+type settingsFlag []string
+
+func (s *settingsFlag) String() string {
+	return strings.Join(*s, ",")
+}
+
+func (s *settingsFlag) Set(value string) error {
+	if !strings.Contains(value, "=") {
+		return fmt.Errorf("expected field=value, got %q", value)
+	}
+	*s = append(*s, value)
+
+	return nil
+}
+
+// updateOpts converts the settings into update options. A value is sent as a
+// string to string fields and as a number to number fields.
+func (s settingsFlag) updateOpts(l7ResourceID int64) (*l7resource.UpdateOpts, error) {
+	if len(s) == 0 {
+		return nil, fmt.Errorf("%w: at least one -set is required", errUsage)
+	}
+
+	opts := &l7resource.UpdateOpts{}
+	for _, setting := range s {
+		field, value, _ := strings.Cut(setting, "=")
+		if field == "l7ResourceId" {
+			return nil, fmt.Errorf("%w: unknown field %q", errUsage, field)
+		}
+
+		err := decodeSetting(opts, field, strconv.Quote(value))
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			if _, numErr := strconv.Atoi(value); numErr != nil {
+				return nil, fmt.Errorf("%w: %s must be a number, got %q", errUsage, field, value)
+			}
+			err = decodeSetting(opts, field, value)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid value of %s: %s", errUsage, field, err)
+		}
+	}
+	opts.L7ResourceID = l7ResourceID
+
+	return opts, nil
+}
+
+// decodeSetting sets a single field of opts to the JSON encoded value.
+func decodeSetting(opts *l7resource.UpdateOpts, field, value string) error {
+	body, err := json.Marshal(map[string]json.RawMessage{field: json.RawMessage(value)})
+	if err != nil {
+		return err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.DisallowUnknownFields()
+
+	return decoder.Decode(opts)
+}
+
+// parseID parses an identifier argument.
+func parseID(name, value string) (int64, error) {
+	id, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("%w: %s must be a positive number, got %q", errUsage, name, value)
+	}
+
+	return id, nil
+}
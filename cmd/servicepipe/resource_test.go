@@ -0,0 +1,67 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestSettingsFlagUpdateOpts(t *testing.T) {
+	tests := map[string]struct {
+		settings settingsFlag
+		want     string
+		wantErr  bool
+	}{
+		"string field":          {settings: settingsFlag{"cdnHost=cdn.example.com"}, want: `{"l7ResourceId":1,"cdnHost":"cdn.example.com"}`},
+		"number field":          {settings: settingsFlag{"forcessl=1"}, want: `{"l7ResourceId":1,"forcessl":1}`},
+		"zero number":           {settings: settingsFlag{"forcessl=0"}, want: `{"l7ResourceId":1,"forcessl":0}`},
+		"number of string":      {settings: settingsFlag{"protectedIp=1"}, want: `{"l7ResourceId":1,"protectedIp":"1"}`},
+		"empty string":          {settings: settingsFlag{"cdnHost="}, want: `{"l7ResourceId":1,"cdnHost":""}`},
+		"value with equals":     {settings: settingsFlag{"geoipList=RU=BY"}, want: `{"l7ResourceId":1,"geoipList":"RU=BY"}`},
+		"quotes in value":       {settings: settingsFlag{`cdnHost="x"`}, want: `{"l7ResourceId":1,"cdnHost":"\"x\""}`},
+		"several fields":        {settings: settingsFlag{"forcessl=1", "geoipList=RU,BY"}, want: `{"l7ResourceId":1,"forcessl":1,"geoipList":"RU,BY"}`},
+		"last value wins":       {settings: settingsFlag{"forcessl=1", "forcessl=0"}, want: `{"l7ResourceId":1,"forcessl":0}`},
+		"no settings":           {wantErr: true},
+		"unknown field":         {settings: settingsFlag{"forceHttps=1"}, wantErr: true},
+		"identifier":            {settings: settingsFlag{"l7ResourceId=2"}, wantErr: true},
+		"text of number field":  {settings: settingsFlag{"forcessl=yes"}, wantErr: true},
+		"float of number field": {settings: settingsFlag{"forcessl=1.5"}, wantErr: true},
+		"empty number":          {settings: settingsFlag{"forcessl="}, wantErr: true},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			opts, err := test.settings.updateOpts(1)
+			if test.wantErr {
+				if !errors.Is(err, errUsage) {
+					t.Errorf("got error %v, want a usage error", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := json.Marshal(opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != test.want {
+				t.Errorf("got %s, want %s", got, test.want)
+			}
+		})
+	}
+}
+
+func TestSettingsFlagSet(t *testing.T) {
+	var settings settingsFlag
+	if err := settings.Set("forcessl"); err == nil {
+		t.Error("got no error of a setting without a value, want one")
+	}
+	if err := settings.Set("forcessl=1"); err != nil {
+		t.Fatal(err)
+	}
+	if got := settings.String(); got != "forcessl=1" {
+		t.Errorf("got %q, want forcessl=1", got)
+	}
+}
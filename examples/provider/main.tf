@@ -13,11 +13,11 @@ provider "servicepipe" {
 
 resource "servicepipe_l7resource" "test" {
   l7_resource_name = "test.example.com"
-  www_redir        = 1
-  http_2_https     = 1
-  force_ssl        = 0
+  www_redir        = true
+  http_2_https     = true
+  force_ssl        = false
 
-  use_custom_ssl = 1
+  use_custom_ssl = true
   custom_ssl_key = base64encode(var.test_example_com_key)
   custom_ssl_crt = base64encode(var.test_example_com_crt)
 
@@ -32,6 +32,11 @@ resource "servicepipe_l7resource" "test" {
     #   weight = 50
     #   mode   = "primary"
     # },
+    # {
+    #   hostname = "backend.example.com"
+    #   weight   = 50
+    #   mode     = "backup"
+    # },
   ]
 }
 
@@ -0,0 +1,221 @@
+package sdkv1
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// AuditOutcomeSuccess is the outcome of a mutating call the API accepted.
+	AuditOutcomeSuccess = "success"
+
+	// AuditOutcomeFailure is the outcome of a mutating call that failed.
+	AuditOutcomeFailure = "failure"
+)
+
+// auditRedactedValue replaces values of AuditRedactedFields.
+const auditRedactedValue = "REDACTED"
+
+// AuditRedactedFields contains JSON fields whose values are never written to
+// audit events, such as SSL material.
+var AuditRedactedFields = map[string]bool{
+	"customSslKey": true,
+	"customSslCrt": true,
+}
+
+// Auditor records mutating calls of l7resource and l7origin made with the
+// client. Audit is called once per call, also by concurrent operations.
+type Auditor interface {
+	Audit(ctx context.Context, event *AuditEvent)
+}
+
+// AuditEvent represents a single mutating API call.
+type AuditEvent struct {
+	// Time is the time the call finished.
+	Time time.Time `json:"time"`
+
+	// Address identifies the Terraform resource that made the call, see
+	// WithAuditAddress.
+	Address string `json:"address,omitempty"`
+
+	// Operation is the SDK function, such as l7resource.Update.
+	Operation string `json:"operation"`
+
+	// Method is the HTTP method of the call.
+	Method string `json:"method"`
+
+	// TargetIDs contains the identifiers of the changed objects by their JSON
+	// names, such as l7ResourceId.
+	TargetIDs map[string]int64 `json:"targetIds"`
+
+	// Changes contains the changed fields, values of AuditRedactedFields are
+	// redacted.
+	Changes []AuditChange `json:"changes,omitempty"`
+
+	// Outcome is AuditOutcomeSuccess or AuditOutcomeFailure.
+	Outcome string `json:"outcome"`
+
+	// StatusCode is the status code of the response, zero when there was none.
+	StatusCode int `json:"statusCode,omitempty"`
+
+	// Error is the error of a failed call.
+	Error string `json:"error,omitempty"`
+}
+
+// AuditChange represents a changed field of a mutating call.
+type AuditChange struct {
+	Field string `json:"field"`
+	Old   any    `json:"old"`
+	New   any    `json:"new"`
+}
+
+type auditAddressKey struct{}
+
+// WithAuditAddress returns a context whose mutating calls are recorded with
+// the address.
+func WithAuditAddress(ctx context.Context, address string) context.Context {
+	return context.WithValue(ctx, auditAddressKey{}, address)
+}
+
+// AuditAddress returns the address set by WithAuditAddress.
+func AuditAddress(ctx context.Context) string {
+	address, _ := ctx.Value(auditAddressKey{}).(string)
+	return address
+}
+
+// Audit completes the event with the time, the address of the context and the
+// outcome of the call, and passes it to the auditor of the client. It does
+// nothing when the client has no auditor.
+func (client *Client) Audit(ctx context.Context, event *AuditEvent, responseResult *ResponseResult, err error) {
+	if client.Auditor == nil {
+		return
+	}
+
+	event.Time = time.Now().UTC()
+	event.Address = AuditAddress(ctx)
+	event.Outcome = AuditOutcomeSuccess
+	if responseResult != nil && responseResult.Response != nil {
+		event.StatusCode = responseResult.StatusCode
+	}
+	if err != nil {
+		event.Outcome = AuditOutcomeFailure
+		event.Error = err.Error()
+	}
+
+	client.Auditor.Audit(ctx, event)
+}
+
+// AuditDiff returns the fields of new whose values differ from the ones of
+// old, both are compared by their JSON representation. Fields missing in new
+// are not changed, a nil old has no fields.
+func AuditDiff(old, new any) []AuditChange {
+	oldFields := auditFields(old)
+	newFields := auditFields(new)
+
+	keys := make([]string, 0, len(newFields))
+	for key := range newFields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var changes []AuditChange
+	for _, key := range keys {
+		oldValue, newValue := oldFields[key], newFields[key]
+		if reflect.DeepEqual(oldValue, newValue) {
+			continue
+		}
+
+		if AuditRedactedFields[key] {
+			oldValue, newValue = auditRedact(oldValue), auditRedact(newValue)
+		}
+		changes = append(changes, AuditChange{Field: key, Old: oldValue, New: newValue})
+	}
+
+	return changes
+}
+
+// auditFields returns the JSON object of the value, nil when it is not one.
+func auditFields(value any) map[string]any {
+	if value == nil || reflect.ValueOf(value).Kind() == reflect.Pointer && reflect.ValueOf(value).IsNil() {
+		return nil
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil
+	}
+
+	var fields map[string]any
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil
+	}
+
+	return fields
+}
+
+// auditRedact returns the redacted value, empty values are kept so the change
+// shows whether a value was set or removed.
+func auditRedact(value any) any {
+	if value == nil || value == "" {
+		return value
+	}
+
+	return auditRedactedValue
+}
+
+// FileAuditor is an Auditor appending a JSON line per event to a file. Every
+// line is written with a single write to a file opened for appending, so lines
+// of concurrent operations never interleave.
+type FileAuditor struct {
+	// OnError is called when an event could not be written, errors are
+	// dropped when it is nil.
+	OnError func(ctx context.Context, err error)
+
+	mu   sync.Mutex
+	file *os.File
+}
+
+var _ Auditor = &FileAuditor{}
+
+// NewFileAuditor opens the audit log file for appending, it is created when it
+// does not exist.
+func NewFileAuditor(path string) (*FileAuditor, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FileAuditor{file: file}, nil
+}
+
+// Audit appends the event to the file.
+func (a *FileAuditor) Audit(ctx context.Context, event *AuditEvent) {
+	line, err := json.Marshal(event)
+	if err == nil {
+		a.mu.Lock()
+		_, err = a.file.Write(append(line, '\n'))
+		a.mu.Unlock()
+	}
+
+	if err != nil && a.OnError != nil {
+		a.OnError(ctx, err)
+	}
+}
+
+// Close closes the file.
+func (a *FileAuditor) Close() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return a.file.Close()
+}
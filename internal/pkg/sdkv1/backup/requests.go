@@ -0,0 +1,297 @@
+package backup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	v1 "terraform-provider-servicepipe/internal/pkg/sdkv1"
+	l7origin "terraform-provider-servicepipe/internal/pkg/sdkv1/l7origin"
+	l7resource "terraform-provider-servicepipe/internal/pkg/sdkv1/l7resource"
+)
+
+// ErrUnsupportedVersion is returned by Restore when the snapshot was written in
+// an unknown format.
+var ErrUnsupportedVersion = errors.New("sp-go: unsupported snapshot version")
+
+// Backup returns a snapshot of all domains of the account and their origins,
+// ordered by domain names and origin IDs. Snapshots never contain SSL keys and
+// certificates.
+func Backup(ctx context.Context, client *v1.Client) (*Snapshot, *v1.ResponseResult, error) {
+	items, responseResult, err := l7resource.ListAll(ctx, client, nil)
+	if err != nil {
+		return nil, responseResult, err
+	}
+
+	snapshot := &Snapshot{
+		Version:   SnapshotVersion,
+		CreatedAt: time.Now().Unix(),
+		Resources: make([]Resource, 0, len(items)),
+	}
+	for _, item := range items {
+		origins, responseResult, err := l7origin.ListAll(ctx, client, &l7origin.ListOpts{L7ResourceID: item.L7ResourceID})
+		if err != nil {
+			return nil, responseResult, err
+		}
+
+		resource := Resource{
+			Resource: *item,
+			Origins:  make([]l7origin.Item, 0, len(origins)),
+		}
+		resource.Resource.CustomSslKey = ""
+		resource.Resource.CustomSslCrt = ""
+		for _, origin := range origins {
+			resource.Origins = append(resource.Origins, *origin)
+		}
+		sort.Slice(resource.Origins, func(i, j int) bool {
+			return resource.Origins[i].ID < resource.Origins[j].ID
+		})
+
+		snapshot.Resources = append(snapshot.Resources, resource)
+	}
+	sort.Slice(snapshot.Resources, func(i, j int) bool {
+		return snapshot.Resources[i].Resource.L7ResourceName < snapshot.Resources[j].Resource.L7ResourceName
+	})
+
+	return snapshot, nil, nil
+}
+
+// Restore makes the account match the snapshot: missing domains are created,
+// settings of existing domains are updated and origins are reconciled by their
+// IPs. Domains are matched by their names, so a snapshot can be restored into
+// a different account. Domains that are not in the snapshot are kept.
+//
+// Snapshots have no SSL keys and certificates, so Restore keeps the current
+// ones and doesn't turn custom SSL on, a skip operation is returned instead.
+//
+// Restore returns the operations it made, or the operations it would make when
+// opts.DryRun is set. On error the operations made so far are returned.
+func Restore(ctx context.Context, client *v1.Client, snapshot *Snapshot, opts *RestoreOpts) ([]*Operation, *v1.ResponseResult, error) {
+	if snapshot.Version != SnapshotVersion {
+		return nil, nil, fmt.Errorf("%w: %d", ErrUnsupportedVersion, snapshot.Version)
+	}
+
+	items, responseResult, err := l7resource.ListAll(ctx, client, nil)
+	if err != nil {
+		return nil, responseResult, err
+	}
+
+	var operations []*Operation
+	for i := range snapshot.Resources {
+		wanted := &snapshot.Resources[i]
+
+		var current *l7resource.Item
+		for _, item := range items {
+			if l7resource.NamesEqual(item.L7ResourceName, wanted.Resource.L7ResourceName) {
+				current = item
+				break
+			}
+		}
+
+		resourceOperations, responseResult, err := restoreResource(ctx, client, wanted, current, opts.DryRun)
+		operations = append(operations, resourceOperations...)
+		if err != nil {
+			return operations, responseResult, err
+		}
+	}
+
+	return operations, nil, nil
+}
+
+// restoreResource restores a single domain, current is nil when the domain
+// doesn't exist.
+func restoreResource(ctx context.Context, client *v1.Client, wanted *Resource, current *l7resource.Item, dryRun bool) ([]*Operation, *v1.ResponseResult, error) {
+	name := wanted.Resource.L7ResourceName
+
+	var operations []*Operation
+	var currentOrigins []*l7origin.Item
+	created := current == nil
+	if created {
+		primary := primaryOrigin(wanted.Origins)
+		if primary == nil {
+			return nil, nil, fmt.Errorf("sp-go: l7 resource %s has no origins to be created with", name)
+		}
+
+		operation := &Operation{Action: ActionCreateResource, L7ResourceName: name, IP: primary.IP}
+		createOpts := &l7resource.CreateOpts{
+			L7ResourceName: name,
+			OriginData:     primary.IP,
+			Wwwredir:       wanted.Resource.Wwwredir,
+		}
+		if dryRun {
+			// The API creates the domain with an origin of the creation IP,
+			// its weight and mode are not known until then.
+			current = &l7resource.Item{L7ResourceName: name, Wwwredir: wanted.Resource.Wwwredir}
+			currentOrigins = []*l7origin.Item{{IP: primary.IP}}
+		} else {
+			response, responseResult, err := l7resource.Create(ctx, client, createOpts)
+			if err != nil {
+				return nil, responseResult, err
+			}
+			current = &response.Data.Result
+			operation.L7ResourceID = current.L7ResourceID
+		}
+		operations = append(operations, operation)
+	}
+
+	if currentOrigins == nil {
+		origins, responseResult, err := l7origin.ListAll(ctx, client, &l7origin.ListOpts{L7ResourceID: current.L7ResourceID})
+		if err != nil {
+			return operations, responseResult, err
+		}
+		currentOrigins = origins
+	}
+
+	item := wanted.Resource
+	item.L7ResourceName = current.L7ResourceName
+	item.CustomSslKey = current.CustomSslKey
+	item.CustomSslCrt = current.CustomSslCrt
+	if item.UseCustomSsl != 0 && (created || current.UseCustomSsl == 0) {
+		item.UseCustomSsl = 0
+		operations = append(operations, &Operation{
+			Action:         ActionSkipCustomSsl,
+			L7ResourceName: name,
+			L7ResourceID:   current.L7ResourceID,
+		})
+	}
+
+	// Created domains get defaults of the API that are not known in a dry
+	// run, so every setting is set in both runs and their operations match.
+	changes := l7resource.Diff(current, &item)
+	if created {
+		changes = createdSettings(&item)
+	}
+	if !changes.IsEmpty() {
+		operation := &Operation{
+			Action:         ActionUpdateResource,
+			L7ResourceName: name,
+			L7ResourceID:   current.L7ResourceID,
+			Fields:         changes.Fields(),
+		}
+		if !dryRun {
+			updateOpts := changes.UpdateOpts(current.L7ResourceID)
+			updateOpts.IfModifiedAt = current.ModifiedAt
+			if _, responseResult, err := l7resource.Patch(ctx, client, updateOpts); err != nil {
+				return operations, responseResult, err
+			}
+		}
+		operations = append(operations, operation)
+	}
+
+	originOperations, responseResult, err := restoreOrigins(ctx, client, current.L7ResourceID, name, wanted.Origins, currentOrigins, dryRun)
+	operations = append(operations, originOperations...)
+
+	return operations, responseResult, err
+}
+
+// restoreOrigins reconciles origins of a domain by their IPs. Missing origins
+// are created and changed origins updated before extra origins are deleted, so
+// the domain keeps serving traffic.
+func restoreOrigins(ctx context.Context, client *v1.Client, l7ResourceID int64, name string, wanted []l7origin.Item, current []*l7origin.Item, dryRun bool) ([]*Operation, *v1.ResponseResult, error) {
+	currentByIP := make(map[string]*l7origin.Item, len(current))
+	for _, origin := range current {
+		currentByIP[origin.IP] = origin
+	}
+
+	var operations []*Operation
+	wantedIPs := make(map[string]bool, len(wanted))
+	for _, origin := range wanted {
+		wantedIPs[origin.IP] = true
+
+		operation := &Operation{
+			L7ResourceName: name,
+			L7ResourceID:   l7ResourceID,
+			IP:             origin.IP,
+			Weight:         origin.Weight,
+			Mode:           origin.Mode,
+		}
+
+		existing, ok := currentByIP[origin.IP]
+		switch {
+		case !ok:
+			operation.Action = ActionCreateOrigin
+			if !dryRun {
+				created, responseResult, err := l7origin.Create(ctx, client, &l7origin.CreateOpts{
+					L7ResourceID: l7ResourceID,
+					IP:           origin.IP,
+					Weight:       origin.Weight,
+					Mode:         origin.Mode,
+				})
+				if err != nil {
+					return operations, responseResult, err
+				}
+				operation.OriginID = created.Data.Result.ID
+			}
+		case existing.Weight != origin.Weight || existing.Mode != origin.Mode:
+			operation.Action = ActionUpdateOrigin
+			operation.OriginID = existing.ID
+			if !dryRun {
+				item := *existing
+				item.L7ResourceID = l7ResourceID
+				item.Weight = origin.Weight
+				item.Mode = origin.Mode
+				if _, responseResult, err := l7origin.UpdateFrom(ctx, client, existing, &item); err != nil {
+					return operations, responseResult, err
+				}
+			}
+		default:
+			continue
+		}
+		operations = append(operations, operation)
+	}
+
+	for _, origin := range current {
+		if wantedIPs[origin.IP] {
+			continue
+		}
+
+		operation := &Operation{
+			Action:         ActionDeleteOrigin,
+			L7ResourceName: name,
+			L7ResourceID:   l7ResourceID,
+			OriginID:       origin.ID,
+			IP:             origin.IP,
+		}
+		if !dryRun {
+			_, responseResult, err := l7origin.Delete(ctx, client, &l7origin.DeleteOpts{L7ResourceID: l7ResourceID, ID: origin.ID})
+			if err != nil {
+				return operations, responseResult, err
+			}
+		}
+		operations = append(operations, operation)
+	}
+
+	return operations, nil, nil
+}
+
+// createdSettings returns the settings set on a created domain, its name and SSL
+// material are set by the creation already.
+func createdSettings(item *l7resource.Item) l7resource.ChangeSet {
+	var changes l7resource.ChangeSet
+	for _, change := range l7resource.Settings(item) {
+		switch change.Field {
+		case "l7ResourceName", "customSslKey", "customSslCrt":
+			continue
+		}
+		changes = append(changes, change)
+	}
+
+	return changes
+}
+
+// primaryOrigin returns the first primary origin, or the first origin when no
+// origin is primary.
+func primaryOrigin(origins []l7origin.Item) *l7origin.Item {
+	for i := range origins {
+		if origins[i].Mode == l7origin.ModePrimary {
+			return &origins[i]
+		}
+	}
+	if len(origins) > 0 {
+		return &origins[0]
+	}
+
+	return nil
+}
@@ -0,0 +1,8 @@
+package backup
+
+// RestoreOpts represents options to restore a snapshot.
+type RestoreOpts struct {
+	// DryRun makes Restore return the planned operations without changing
+	// anything.
+	DryRun bool
+}
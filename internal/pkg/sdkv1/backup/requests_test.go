@@ -0,0 +1,428 @@
+package backup_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	v1 "terraform-provider-servicepipe/internal/pkg/sdkv1"
+	"terraform-provider-servicepipe/internal/pkg/sdkv1/backup"
+	"terraform-provider-servicepipe/internal/pkg/sdkv1/l7origin"
+	"terraform-provider-servicepipe/internal/pkg/sdkv1/l7resource"
+)
+
+// fakeAPI serves domains and origins of an account in memory and records the
+// calls that change them.
+type fakeAPI struct {
+	mu      sync.Mutex
+	domains map[int64]l7resource.Item
+	origins map[int64]l7origin.Item
+	nextID  int64
+	writes  []string
+}
+
+// newFakeAPI returns a client of a fake API holding the domains and the
+// origins, the L7ResourceID of origins is their domain.
+func newFakeAPI(t *testing.T, domains []l7resource.Item, origins []l7origin.Item) (*fakeAPI, *v1.Client) {
+	t.Helper()
+
+	api := &fakeAPI{
+		domains: make(map[int64]l7resource.Item),
+		origins: make(map[int64]l7origin.Item),
+		nextID:  100,
+	}
+	for _, domain := range domains {
+		api.domains[domain.L7ResourceID] = domain
+	}
+	for _, origin := range origins {
+		api.origins[origin.ID] = origin
+	}
+
+	server := httptest.NewServer(api)
+	t.Cleanup(server.Close)
+
+	return api, v1.NewClientV1WithCustomHTTP(server.Client(), "token", server.URL)
+}
+
+func (api *fakeAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+
+	if r.Method != http.MethodGet {
+		api.writes = append(api.writes, r.Method+" "+r.URL.Path)
+	}
+
+	var response any
+	var err error
+	switch {
+	case r.URL.Path == "/l7/resource":
+		response, err = api.serveDomains(r)
+	case strings.HasPrefix(r.URL.Path, "/l7/resource/"):
+		id, _ := strconv.ParseInt(strings.TrimPrefix(r.URL.Path, "/l7/resource/"), 10, 64)
+		response = l7resource.Data{Data: l7resource.Result{Result: api.domains[id]}}
+	case r.URL.Path == "/l7/origin":
+		response, err = api.serveOrigins(r)
+	default:
+		err = fmt.Errorf("unexpected path %s", r.URL.Path)
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (api *fakeAPI) serveDomains(r *http.Request) (any, error) {
+	switch r.Method {
+	case http.MethodGet:
+		body := l7resource.DataItems{}
+		body.DataItems.ResultItems.Items = []l7resource.Item{}
+		if r.URL.Query().Get("page") == "1" {
+			for _, domain := range api.domains {
+				body.DataItems.ResultItems.Items = append(body.DataItems.ResultItems.Items, domain)
+			}
+		}
+		body.DataItems.ResultItems.Info.TotalCount = int64(len(api.domains))
+		return body, nil
+	case http.MethodPost:
+		var opts l7resource.CreateOpts
+		if err := json.NewDecoder(r.Body).Decode(&opts); err != nil {
+			return nil, err
+		}
+
+		// The API enables HTTP/2 of created domains and adds the origin.
+		api.nextID++
+		domain := l7resource.Item{
+			L7ResourceID:   api.nextID,
+			L7ResourceName: opts.L7ResourceName,
+			Wwwredir:       opts.Wwwredir,
+			ServiceHTTP2:   1,
+			ModifiedAt:     1,
+		}
+		api.domains[domain.L7ResourceID] = domain
+
+		api.nextID++
+		api.origins[api.nextID] = l7origin.Item{
+			L7ResourceID: domain.L7ResourceID,
+			ID:           api.nextID,
+			IP:           opts.OriginData,
+			Weight:       l7origin.MaxWeight,
+			Mode:         l7origin.ModePrimary,
+		}
+
+		return l7resource.Data{Data: l7resource.Result{Result: domain}}, nil
+	case http.MethodPut:
+		var id struct {
+			L7ResourceID int64 `json:"l7ResourceId"`
+		}
+		var body json.RawMessage
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(body, &id); err != nil {
+			return nil, err
+		}
+
+		domain, ok := api.domains[id.L7ResourceID]
+		if !ok {
+			return nil, fmt.Errorf("unknown domain %d", id.L7ResourceID)
+		}
+		if err := json.Unmarshal(body, &domain); err != nil {
+			return nil, err
+		}
+		domain.ModifiedAt++
+		api.domains[domain.L7ResourceID] = domain
+
+		return l7resource.Data{Data: l7resource.Result{Result: domain}}, nil
+	}
+
+	return nil, fmt.Errorf("unexpected method %s", r.Method)
+}
+
+func (api *fakeAPI) serveOrigins(r *http.Request) (any, error) {
+	switch r.Method {
+	case http.MethodGet:
+		l7ResourceID, _ := strconv.ParseInt(r.URL.Query().Get("l7ResourceId"), 10, 64)
+		body := l7origin.DataItems{}
+		body.DataItems.ResultItems.Items = []l7origin.Item{}
+		if r.URL.Query().Get("page") == "1" {
+			for _, origin := range api.origins {
+				if origin.L7ResourceID == l7ResourceID {
+					// The API does not return the resource identifier of
+					// origins.
+					origin.L7ResourceID = 0
+					body.DataItems.ResultItems.Items = append(body.DataItems.ResultItems.Items, origin)
+				}
+			}
+		}
+		return body, nil
+	case http.MethodPost:
+		var opts l7origin.CreateOpts
+		if err := json.NewDecoder(r.Body).Decode(&opts); err != nil {
+			return nil, err
+		}
+
+		api.nextID++
+		origin := l7origin.Item{L7ResourceID: opts.L7ResourceID, ID: api.nextID, IP: opts.IP, Weight: opts.Weight, Mode: opts.Mode}
+		api.origins[origin.ID] = origin
+
+		return l7origin.Data{Data: l7origin.Result{Result: origin}}, nil
+	case http.MethodPut:
+		var origin l7origin.Item
+		if err := json.NewDecoder(r.Body).Decode(&origin); err != nil {
+			return nil, err
+		}
+		api.origins[origin.ID] = origin
+
+		return l7origin.Data{Data: l7origin.Result{Result: origin}}, nil
+	case http.MethodDelete:
+		var opts l7origin.DeleteOpts
+		if err := json.NewDecoder(r.Body).Decode(&opts); err != nil {
+			return nil, err
+		}
+		delete(api.origins, opts.ID)
+
+		return l7origin.DataDelete{Data: l7origin.ResultDelete{Result: "ok"}}, nil
+	}
+
+	return nil, fmt.Errorf("unexpected method %s", r.Method)
+}
+
+// domainOrigins returns the origins of the domain as "ip weight mode" sorted
+// by IP.
+func (api *fakeAPI) domainOrigins(l7ResourceID int64) []string {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+
+	var origins []string
+	for _, origin := range api.origins {
+		if origin.L7ResourceID == l7ResourceID {
+			origins = append(origins, fmt.Sprintf("%s %d %s", origin.IP, origin.Weight, origin.Mode))
+		}
+	}
+	sort.Strings(origins)
+
+	return origins
+}
+
+// domainByName returns the domain with the name.
+func (api *fakeAPI) domainByName(t *testing.T, name string) l7resource.Item {
+	t.Helper()
+	api.mu.Lock()
+	defer api.mu.Unlock()
+
+	for _, domain := range api.domains {
+		if domain.L7ResourceName == name {
+			return domain
+		}
+	}
+	t.Fatalf("no domain %s", name)
+
+	return l7resource.Item{}
+}
+
+// operationStrings returns the human readable operations.
+func operationStrings(operations []*backup.Operation) []string {
+	result := make([]string, 0, len(operations))
+	for _, operation := range operations {
+		result = append(result, operation.String())
+	}
+
+	return result
+}
+
+func TestBackup(t *testing.T) {
+	_, client := newFakeAPI(t,
+		[]l7resource.Item{
+			{L7ResourceID: 2, L7ResourceName: "b.example.com", Forcessl: 1},
+			{L7ResourceID: 1, L7ResourceName: "a.example.com", UseCustomSsl: 1, CustomSslKey: "key", CustomSslCrt: "crt"},
+		},
+		[]l7origin.Item{
+			{L7ResourceID: 1, ID: 12, IP: "198.51.100.2", Weight: 50, Mode: l7origin.ModePrimary},
+			{L7ResourceID: 1, ID: 11, IP: "198.51.100.1", Weight: 50, Mode: l7origin.ModePrimary},
+			{L7ResourceID: 2, ID: 21, IP: "198.51.100.3", Weight: 100, Mode: l7origin.ModePrimary},
+		},
+	)
+
+	snapshot, _, err := backup.Backup(context.Background(), client)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if snapshot.Version != backup.SnapshotVersion {
+		t.Errorf("got version %d, want %d", snapshot.Version, backup.SnapshotVersion)
+	}
+	if len(snapshot.Resources) != 2 {
+		t.Fatalf("got %d resources, want 2", len(snapshot.Resources))
+	}
+
+	first, second := snapshot.Resources[0], snapshot.Resources[1]
+	if first.Resource.L7ResourceName != "a.example.com" || second.Resource.L7ResourceName != "b.example.com" {
+		t.Errorf("got resources %s, %s, want them ordered by name", first.Resource.L7ResourceName, second.Resource.L7ResourceName)
+	}
+	if first.Resource.UseCustomSsl != 1 || first.Resource.CustomSslKey != "" || first.Resource.CustomSslCrt != "" {
+		t.Errorf("got SSL settings %d, %q, %q, want custom SSL without key and certificate",
+			first.Resource.UseCustomSsl, first.Resource.CustomSslKey, first.Resource.CustomSslCrt)
+	}
+
+	var ids []int64
+	for _, origin := range first.Origins {
+		ids = append(ids, origin.ID)
+	}
+	if !reflect.DeepEqual(ids, []int64{11, 12}) {
+		t.Errorf("got origins %v, want them ordered by ID", ids)
+	}
+	if len(second.Origins) != 1 || second.Origins[0].IP != "198.51.100.3" {
+		t.Errorf("got origins %v of %s", second.Origins, second.Resource.L7ResourceName)
+	}
+}
+
+func TestRestoreExistingDomain(t *testing.T) {
+	domains := []l7resource.Item{
+		{L7ResourceID: 1, L7ResourceName: "example.com", CustomSslKey: "key", ModifiedAt: 1},
+	}
+	origins := []l7origin.Item{
+		{L7ResourceID: 1, ID: 11, IP: "198.51.100.1", Weight: 50, Mode: l7origin.ModePrimary},
+		{L7ResourceID: 1, ID: 12, IP: "198.51.100.2", Weight: 50, Mode: l7origin.ModePrimary},
+	}
+	snapshot := &backup.Snapshot{
+		Version: backup.SnapshotVersion,
+		Resources: []backup.Resource{{
+			Resource: l7resource.Item{L7ResourceName: "example.com", Forcessl: 1, UseCustomSsl: 1},
+			Origins: []l7origin.Item{
+				{IP: "198.51.100.1", Weight: 100, Mode: l7origin.ModePrimary},
+				{IP: "198.51.100.3", Weight: 10, Mode: l7origin.ModeBackup},
+			},
+		}},
+	}
+	wantOperations := []string{
+		"keep custom SSL of l7 resource example.com disabled, the snapshot has no certificate",
+		"update l7 resource example.com: forcessl",
+		"update origin 198.51.100.1 of example.com to mode primary and weight 100",
+		"create origin 198.51.100.3 of example.com with mode backup and weight 10",
+		"delete origin 198.51.100.2 of example.com",
+	}
+
+	api, client := newFakeAPI(t, domains, origins)
+	dryRun, _, err := backup.Restore(context.Background(), client, snapshot, &backup.RestoreOpts{DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(api.writes) != 0 {
+		t.Errorf("got write calls %v in a dry run, want none", api.writes)
+	}
+
+	operations, _, err := backup.Restore(context.Background(), client, snapshot, &backup.RestoreOpts{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := operationStrings(operations); !reflect.DeepEqual(got, wantOperations) {
+		t.Errorf("got operations:\n%q\nwant:\n%q", got, wantOperations)
+	}
+	if !reflect.DeepEqual(operationStrings(dryRun), operationStrings(operations)) {
+		t.Errorf("got dry run operations:\n%q\nwant:\n%q", operationStrings(dryRun), operationStrings(operations))
+	}
+
+	domain := api.domainByName(t, "example.com")
+	if domain.Forcessl != 1 || domain.UseCustomSsl != 0 || domain.CustomSslKey != "key" {
+		t.Errorf("got forcessl %d, useCustomSsl %d and customSslKey %q, want 1, 0 and the kept key",
+			domain.Forcessl, domain.UseCustomSsl, domain.CustomSslKey)
+	}
+	wantOrigins := []string{"198.51.100.1 100 primary", "198.51.100.3 10 backup"}
+	if got := api.domainOrigins(1); !reflect.DeepEqual(got, wantOrigins) {
+		t.Errorf("got origins %v, want %v", got, wantOrigins)
+	}
+
+	// A restored account has nothing left to restore.
+	again, _, err := backup.Restore(context.Background(), client, snapshot, &backup.RestoreOpts{DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := operationStrings(again); len(got) != 1 || !strings.HasPrefix(got[0], "keep custom SSL") {
+		t.Errorf("got operations %q after the restore, want only the skipped custom SSL", got)
+	}
+}
+
+func TestRestoreNewDomain(t *testing.T) {
+	snapshot := &backup.Snapshot{
+		Version: backup.SnapshotVersion,
+		Resources: []backup.Resource{{
+			Resource: l7resource.Item{L7ResourceName: "example.com", Forcessl: 1, Wwwredir: 1},
+			Origins: []l7origin.Item{
+				{IP: "198.51.100.2", Weight: 1, Mode: l7origin.ModeBackup},
+				{IP: "198.51.100.1", Weight: 60, Mode: l7origin.ModePrimary},
+			},
+		}},
+	}
+
+	api, client := newFakeAPI(t, nil, nil)
+	dryRun, _, err := backup.Restore(context.Background(), client, snapshot, &backup.RestoreOpts{DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(api.writes) != 0 {
+		t.Errorf("got write calls %v in a dry run, want none", api.writes)
+	}
+
+	operations, _, err := backup.Restore(context.Background(), client, snapshot, &backup.RestoreOpts{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(operations) == 0 || operations[0].Action != backup.ActionCreateResource || operations[0].IP != "198.51.100.1" {
+		t.Fatalf("got operations %q, want the domain created with the primary origin first", operationStrings(operations))
+	}
+	if operations[1].Action != backup.ActionUpdateResource {
+		t.Fatalf("got operation %q, want the settings of the created domain", operations[1])
+	}
+
+	// Settings are set whatever the defaults of the API are, so the dry run
+	// lists the same fields.
+	if !reflect.DeepEqual(dryRun[1].Fields, operations[1].Fields) {
+		t.Errorf("got dry run fields %v, want %v", dryRun[1].Fields, operations[1].Fields)
+	}
+	for _, field := range []string{"forcessl", "serviceHttp2", "wwwredir"} {
+		found := false
+		for _, changed := range operations[1].Fields {
+			found = found || changed == field
+		}
+		if !found {
+			t.Errorf("got fields %v, want %s", operations[1].Fields, field)
+		}
+	}
+
+	domain := api.domainByName(t, "example.com")
+	if domain.Forcessl != 1 || domain.ServiceHTTP2 != 0 || domain.Wwwredir != 1 {
+		t.Errorf("got forcessl %d, serviceHttp2 %d and wwwredir %d, want 1, 0 and 1",
+			domain.Forcessl, domain.ServiceHTTP2, domain.Wwwredir)
+	}
+	wantOrigins := []string{"198.51.100.1 60 primary", "198.51.100.2 1 backup"}
+	if got := api.domainOrigins(domain.L7ResourceID); !reflect.DeepEqual(got, wantOrigins) {
+		t.Errorf("got origins %v, want %v", got, wantOrigins)
+	}
+}
+
+func TestRestoreUnsupportedVersion(t *testing.T) {
+	api, client := newFakeAPI(t, nil, nil)
+
+	_, _, err := backup.Restore(context.Background(), client, &backup.Snapshot{Version: backup.SnapshotVersion + 1}, &backup.RestoreOpts{})
+	if !errors.Is(err, backup.ErrUnsupportedVersion) {
+		t.Errorf("got error %v, want %v", err, backup.ErrUnsupportedVersion)
+	}
+	if len(api.writes) != 0 {
+		t.Errorf("got write calls %v, want none", api.writes)
+	}
+}
@@ -0,0 +1,82 @@
+package backup
+
+import (
+	"fmt"
+	"strings"
+
+	l7origin "terraform-provider-servicepipe/internal/pkg/sdkv1/l7origin"
+	l7resource "terraform-provider-servicepipe/internal/pkg/sdkv1/l7resource"
+)
+
+// SnapshotVersion is the version of snapshots written by Backup.
+const SnapshotVersion = 1
+
+// Snapshot represents the configuration of all domains of an account.
+type Snapshot struct {
+	// Version is the version of the snapshot format.
+	Version int `json:"version"`
+
+	// CreatedAt represents Unix timestamp when the snapshot has been created.
+	CreatedAt int64 `json:"createdAt"`
+
+	Resources []Resource `json:"resources"`
+}
+
+// Resource represents a single domain and its origins.
+type Resource struct {
+	Resource l7resource.Item `json:"resource"`
+	Origins  []l7origin.Item `json:"origins"`
+}
+
+// Actions of restore operations.
+const (
+	ActionCreateResource = "create_resource"
+	ActionUpdateResource = "update_resource"
+	ActionCreateOrigin   = "create_origin"
+	ActionUpdateOrigin   = "update_origin"
+	ActionDeleteOrigin   = "delete_origin"
+
+	// ActionSkipCustomSsl is returned for a domain whose custom SSL is kept
+	// disabled, as snapshots have no certificates to enable it with.
+	ActionSkipCustomSsl = "skip_custom_ssl"
+)
+
+// Operation represents a single change made by Restore.
+type Operation struct {
+	Action         string `json:"action"`
+	L7ResourceName string `json:"l7ResourceName"`
+
+	// L7ResourceID is the identifier of the domain, zero when the domain is
+	// not created yet.
+	L7ResourceID int64 `json:"l7ResourceId,omitempty"`
+
+	// Fields contains JSON names of changed domain settings.
+	Fields []string `json:"fields,omitempty"`
+
+	// OriginID is the identifier of the origin, zero when the origin is not
+	// created yet.
+	OriginID int64  `json:"originId,omitempty"`
+	IP       string `json:"ip,omitempty"`
+	Weight   int64  `json:"weight"`
+	Mode     string `json:"mode,omitempty"`
+}
+
+// String returns a human readable representation of the operation.
+func (op *Operation) String() string {
+	switch op.Action {
+	case ActionCreateResource:
+		return fmt.Sprintf("create l7 resource %s with origin %s", op.L7ResourceName, op.IP)
+	case ActionUpdateResource:
+		return fmt.Sprintf("update l7 resource %s: %s", op.L7ResourceName, strings.Join(op.Fields, ", "))
+	case ActionCreateOrigin:
+		return fmt.Sprintf("create origin %s of %s with mode %s and weight %d", op.IP, op.L7ResourceName, op.Mode, op.Weight)
+	case ActionUpdateOrigin:
+		return fmt.Sprintf("update origin %s of %s to mode %s and weight %d", op.IP, op.L7ResourceName, op.Mode, op.Weight)
+	case ActionDeleteOrigin:
+		return fmt.Sprintf("delete origin %s of %s", op.IP, op.L7ResourceName)
+	case ActionSkipCustomSsl:
+		return fmt.Sprintf("keep custom SSL of l7 resource %s disabled, the snapshot has no certificate", op.L7ResourceName)
+	}
+
+	return op.Action + " " + op.L7ResourceName
+}
@@ -0,0 +1,157 @@
+package sdkv1
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultCircuitBreakerThreshold is the default number of consecutive
+	// failed requests that open the circuit breaker.
+	DefaultCircuitBreakerThreshold = 5
+
+	// DefaultCircuitBreakerCoolDown is the default period requests fail fast
+	// for once the circuit breaker is open.
+	DefaultCircuitBreakerCoolDown = 30 * time.Second
+)
+
+// ErrCircuitOpen is returned by requests while the circuit breaker of the
+// client is open.
+var ErrCircuitOpen = errors.New("sp-go: circuit breaker is open")
+
+// CircuitBreaker fails requests fast while the API is degraded. It opens after
+// consecutive requests fail with a transport error, such as a timeout, or a 5xx
+// status code. Requests fail with ErrCircuitOpen for the cool-down period,
+// afterwards the next request probes the API with Client.Echo and the breaker
+// closes when the probe gets a response, it opens for another period
+// otherwise. Requests made while the probe is in flight fail fast as well.
+type CircuitBreaker struct {
+	threshold int
+	coolDown  time.Duration
+
+	mu       sync.Mutex
+	failures int
+	openedAt time.Time
+	lastErr  error
+
+	// probing is set while a request probes the API, the lock is not held
+	// during the probe.
+	probing bool
+}
+
+// NewCircuitBreaker returns a circuit breaker opening after threshold
+// consecutive failed requests for the cool-down period.
+func NewCircuitBreaker(threshold int, coolDown time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{
+		threshold: threshold,
+		coolDown:  coolDown,
+	}
+}
+
+type probeKey struct{}
+
+// allowRequest returns an error while the circuit breaker of the client is
+// open, it probes the API once the cool-down period has passed.
+func (client *Client) allowRequest(ctx context.Context) error {
+	b := client.CircuitBreaker
+	if b == nil || ctx.Value(probeKey{}) != nil {
+		return nil
+	}
+
+	b.mu.Lock()
+	if b.openedAt.IsZero() {
+		b.mu.Unlock()
+		return nil
+	}
+	if wait := b.coolDown - time.Since(b.openedAt); wait > 0 {
+		err := b.openErr(wait)
+		b.mu.Unlock()
+		return err
+	}
+	if b.probing {
+		err := b.probingErr()
+		b.mu.Unlock()
+		return err
+	}
+	b.probing = true
+	b.mu.Unlock()
+
+	_, responseResult, err := client.Echo(context.WithValue(ctx, probeKey{}, true))
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if failed(responseResult, err) {
+		b.openedAt = time.Now()
+		b.lastErr = err
+		return b.openErr(b.coolDown)
+	}
+
+	b.failures = 0
+	b.openedAt = time.Time{}
+	b.lastErr = nil
+
+	return nil
+}
+
+// recordResult counts consecutive failed requests and opens the circuit
+// breaker of the client at the threshold.
+func (client *Client) recordResult(ctx context.Context, response *http.Response, err error) {
+	b := client.CircuitBreaker
+	if b == nil || ctx.Value(probeKey{}) != nil {
+		return
+	}
+
+	// Requests canceled by the caller say nothing about the API.
+	if ctx.Err() != nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if err == nil && response.StatusCode < http.StatusInternalServerError {
+		b.failures = 0
+		return
+	}
+
+	if err == nil {
+		err = fmt.Errorf("got the %d status code from the server", response.StatusCode)
+	}
+	b.failures++
+	b.lastErr = err
+	if b.failures >= b.threshold && b.openedAt.IsZero() {
+		b.openedAt = time.Now()
+	}
+}
+
+// openErr returns the error of a request rejected by the open breaker.
+func (b *CircuitBreaker) openErr(wait time.Duration) error {
+	return fmt.Errorf("%w after %d consecutive failed requests, the API is retried in %s: last error: %v",
+		ErrCircuitOpen, b.failures, wait.Round(time.Second), b.lastErr)
+}
+
+// probingErr returns the error of a request rejected while the breaker probes
+// the API.
+func (b *CircuitBreaker) probingErr() error {
+	return fmt.Errorf("%w after %d consecutive failed requests, the API is being probed: last error: %v",
+		ErrCircuitOpen, b.failures, b.lastErr)
+}
+
+// failed reports whether a request failed with a transport error or a 5xx
+// status code.
+func failed(responseResult *ResponseResult, err error) bool {
+	if responseResult == nil || responseResult.Response == nil {
+		return err != nil
+	}
+
+	return responseResult.StatusCode >= http.StatusInternalServerError
+}
@@ -0,0 +1,68 @@
+package sdkv1_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	v1 "terraform-provider-servicepipe/internal/pkg/sdkv1"
+)
+
+func TestCircuitBreakerProbe(t *testing.T) {
+	var failing atomic.Bool
+	failing.Store(true)
+	probed := make(chan struct{})
+	release := make(chan struct{})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failing.Load() {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		if r.URL.Path == "/l7/resource" {
+			close(probed)
+			<-release
+		}
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	t.Cleanup(server.Close)
+
+	ctx := context.Background()
+	client := v1.NewClientV1WithCustomHTTP(server.Client(), "token", server.URL)
+	client.CircuitBreaker = v1.NewCircuitBreaker(2, time.Millisecond)
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.DoRequest(ctx, http.MethodGet, server.URL+"/l7/origin", nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	time.Sleep(10 * time.Millisecond)
+	failing.Store(false)
+
+	// The first request after the cool-down period probes the API.
+	done := make(chan error, 1)
+	go func() {
+		_, err := client.DoRequest(ctx, http.MethodGet, server.URL+"/l7/origin", nil)
+		done <- err
+	}()
+	<-probed
+
+	// Requests made during the probe fail fast instead of waiting for it.
+	if _, err := client.DoRequest(ctx, http.MethodGet, server.URL+"/l7/origin", nil); !errors.Is(err, v1.ErrCircuitOpen) {
+		t.Errorf("got error %v during the probe, want %v", err, v1.ErrCircuitOpen)
+	}
+
+	close(release)
+	if err := <-done; err != nil {
+		t.Errorf("got error %v of the probing request, want none", err)
+	}
+
+	// The successful probe closes the breaker.
+	if _, err := client.DoRequest(ctx, http.MethodGet, server.URL+"/l7/origin", nil); err != nil {
+		t.Errorf("got error %v after the probe, want none", err)
+	}
+}
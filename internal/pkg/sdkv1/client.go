@@ -68,6 +68,16 @@ type Client struct {
 
 	// UserAgent contains user agent that will be used in all requests.
 	UserAgent string
+
+	// Auditor records mutating calls of l7resource and l7origin when it is set.
+	Auditor Auditor
+
+	// CircuitBreaker fails requests fast while the API is degraded when it is
+	// set.
+	CircuitBreaker *CircuitBreaker
+
+	// resourceLocks serializes mutations per l7 resource.
+	resourceLocks keyedMutex
 }
 
 // NewClientV1 initializes a new client for the ServicePipe API V1.
@@ -145,10 +155,19 @@ func (client *Client) DoRequest(ctx context.Context, method, path string, body i
 	if body != nil {
 		request.Header.Set("Content-Type", "application/json")
 	}
+
+	ctx, span := client.startRequestSpan(ctx, request)
 	request = request.WithContext(ctx)
 
+	if err := client.allowRequest(ctx); err != nil {
+		endRequestSpan(span, nil, err)
+		return nil, err
+	}
+
 	// Send the HTTP request and populate the ResponseResult.
 	response, err := client.HTTPClient.Do(request)
+	endRequestSpan(span, response, err)
+	client.recordResult(ctx, response, err)
 	if err != nil {
 		return nil, err
 	}
@@ -15,6 +15,9 @@ import (
 
 const l7OriginPath = "l7/origin"
 
+// defaultListLimit is the page size used by ListAll.
+const defaultListLimit = 100
+
 // GetByID returns a single resource by its id.
 func GetByID(ctx context.Context, client *v1.Client, l7ResourceID int, ID int) (*Data, *v1.ResponseResult, error) {
 	url := strings.Join([]string{client.Endpoint, l7OriginPath, strconv.Itoa(l7ResourceID), strconv.Itoa(ID)}, "/")
@@ -38,6 +41,58 @@ func GetByID(ctx context.Context, client *v1.Client, l7ResourceID int, ID int) (
 
 // List gets a list of all origins.
 func List(ctx context.Context, client *v1.Client, opts *ListOpts) ([]*Item, *v1.ResponseResult, error) {
+	items, responseResult, err := list(ctx, client, opts)
+	if err != nil {
+		return nil, responseResult, err
+	}
+
+	return convertToSliceOfPointers(items.Items), responseResult, nil
+}
+
+// ListAll gets origins of every page of the list. Opts page is ignored, opts
+// limit is used as the page size. Pages are requested until the total count of
+// the list is reached, or until an empty page when the API doesn't return it,
+// so a page size capped by the API doesn't cut the list. A page without new
+// origins also ends the list, so an API ignoring the page doesn't make it loop
+// or repeat origins.
+func ListAll(ctx context.Context, client *v1.Client, opts *ListOpts) ([]*Item, *v1.ResponseResult, error) {
+	pageOpts := ListOpts{}
+	if opts != nil {
+		pageOpts = *opts
+	}
+	if pageOpts.Limit <= 0 {
+		pageOpts.Limit = defaultListLimit
+	}
+	pageOpts.Page = 1
+
+	var origins []*Item
+	seen := make(map[int64]bool)
+	for {
+		items, responseResult, err := list(ctx, client, &pageOpts)
+		if err != nil {
+			return nil, responseResult, err
+		}
+
+		added := 0
+		for _, item := range convertToSliceOfPointers(items.Items) {
+			if seen[item.ID] {
+				continue
+			}
+			seen[item.ID] = true
+			origins = append(origins, item)
+			added++
+		}
+
+		if added == 0 || items.Info.TotalCount > 0 && int64(len(origins)) >= items.Info.TotalCount {
+			return origins, responseResult, nil
+		}
+
+		pageOpts.Page++
+	}
+}
+
+// list gets a single page of origins.
+func list(ctx context.Context, client *v1.Client, opts *ListOpts) (*Items, *v1.ResponseResult, error) {
 	url := strings.Join([]string{client.Endpoint, l7OriginPath}, "/")
 
 	queryParams, err := query.Values(opts)
@@ -61,12 +116,29 @@ func List(ctx context.Context, client *v1.Client, opts *ListOpts) ([]*Item, *v1.
 	if err != nil {
 		return nil, responseResult, err
 	}
-	convertedItems := convertToSliceOfPointers(dataitems.DataItems.ResultItems.Items)
-	return convertedItems, responseResult, nil
+
+	return &dataitems.DataItems.ResultItems, responseResult, nil
 }
 
-// Create requests a creation of a new domain.
+// Create requests a creation of a new origin.
 func Create(ctx context.Context, client *v1.Client, opts *CreateOpts) (*Data, *v1.ResponseResult, error) {
+	result, responseResult, err := create(ctx, client, opts)
+
+	event := &v1.AuditEvent{
+		Operation: "l7origin.Create",
+		Method:    http.MethodPost,
+		TargetIDs: map[string]int64{"l7ResourceId": opts.L7ResourceID},
+		Changes:   v1.AuditDiff(nil, opts),
+	}
+	if result != nil {
+		event.TargetIDs["id"] = result.Data.Result.ID
+	}
+	client.Audit(ctx, event, responseResult, err)
+
+	return result, responseResult, err
+}
+
+func create(ctx context.Context, client *v1.Client, opts *CreateOpts) (*Data, *v1.ResponseResult, error) {
 	url := strings.Join([]string{client.Endpoint, l7OriginPath}, "/")
 	requestBody, err := json.Marshal(opts)
 	if err != nil {
@@ -97,6 +169,17 @@ func Create(ctx context.Context, client *v1.Client, opts *CreateOpts) (*Data, *v
 
 // Delete deletes a single origin by its id.
 func Delete(ctx context.Context, client *v1.Client, opts *DeleteOpts) (*DataDelete, *v1.ResponseResult, error) {
+	result, responseResult, err := deleteOrigin(ctx, client, opts)
+	client.Audit(ctx, &v1.AuditEvent{
+		Operation: "l7origin.Delete",
+		Method:    http.MethodDelete,
+		TargetIDs: map[string]int64{"l7ResourceId": opts.L7ResourceID, "id": opts.ID},
+	}, responseResult, err)
+
+	return result, responseResult, err
+}
+
+func deleteOrigin(ctx context.Context, client *v1.Client, opts *DeleteOpts) (*DataDelete, *v1.ResponseResult, error) {
 	url := strings.Join([]string{client.Endpoint, l7OriginPath}, "/")
 	requestBody, err := json.Marshal(opts)
 	if err != nil {
@@ -121,8 +204,34 @@ func Delete(ctx context.Context, client *v1.Client, opts *DeleteOpts) (*DataDele
 	return origin, responseResult, nil
 }
 
-// Update deletes a single origin by its id.
+// Update replaces a single origin with the item.
 func Update(ctx context.Context, client *v1.Client, item *Item) (*Data, *v1.ResponseResult, error) {
+	return UpdateFrom(ctx, client, nil, item)
+}
+
+// UpdateFrom replaces a single origin with the item. Previous is the origin
+// before the update, it is only used to record the changed fields when the
+// client records mutating calls, a nil previous records every field.
+func UpdateFrom(ctx context.Context, client *v1.Client, previous, item *Item) (*Data, *v1.ResponseResult, error) {
+	if previous != nil && previous.L7ResourceID == 0 {
+		// The API does not return the resource identifier of origins.
+		withID := *previous
+		withID.L7ResourceID = item.L7ResourceID
+		previous = &withID
+	}
+
+	result, responseResult, err := update(ctx, client, item)
+	client.Audit(ctx, &v1.AuditEvent{
+		Operation: "l7origin.Update",
+		Method:    http.MethodPut,
+		TargetIDs: map[string]int64{"l7ResourceId": item.L7ResourceID, "id": item.ID},
+		Changes:   v1.AuditDiff(previous, item),
+	}, responseResult, err)
+
+	return result, responseResult, err
+}
+
+func update(ctx context.Context, client *v1.Client, item *Item) (*Data, *v1.ResponseResult, error) {
 	url := strings.Join([]string{client.Endpoint, l7OriginPath}, "/")
 	requestBody, err := json.Marshal(item)
 	if err != nil {
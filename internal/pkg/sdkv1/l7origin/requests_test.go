@@ -0,0 +1,95 @@
+package l7origin_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	v1 "terraform-provider-servicepipe/internal/pkg/sdkv1"
+	"terraform-provider-servicepipe/internal/pkg/sdkv1/l7origin"
+)
+
+// newListServer returns a server listing count origins. Pages hold at most
+// pageCap origins whatever the requested limit is, the total count is omitted
+// when withTotal is false and the first page is returned for every page when
+// ignorePage is true.
+func newListServer(t *testing.T, count, pageCap int, withTotal, ignorePage bool) (*httptest.Server, *int) {
+	t.Helper()
+
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		if limit > pageCap {
+			limit = pageCap
+		}
+		if ignorePage {
+			page = 1
+		}
+
+		items := []l7origin.Item{}
+		for id := (page-1)*limit + 1; id <= page*limit && id <= count; id++ {
+			items = append(items, l7origin.Item{ID: int64(id), IP: "198.51.100." + strconv.Itoa(id)})
+		}
+
+		body := l7origin.DataItems{}
+		body.DataItems.ResultItems.Items = items
+		if withTotal {
+			body.DataItems.ResultItems.Info.TotalCount = int64(count)
+		}
+		if err := json.NewEncoder(w).Encode(body); err != nil {
+			t.Error(err)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return server, &requests
+}
+
+func TestListAll(t *testing.T) {
+	tests := map[string]struct {
+		count        int
+		pageCap      int
+		withTotal    bool
+		ignorePage   bool
+		wantCount    int
+		wantRequests int
+	}{
+		"single page":                 {count: 3, pageCap: 100, withTotal: true, wantCount: 3, wantRequests: 1},
+		"several pages":               {count: 250, pageCap: 100, withTotal: true, wantCount: 250, wantRequests: 3},
+		"page size capped by the API": {count: 120, pageCap: 50, withTotal: true, wantCount: 120, wantRequests: 3},
+		"without total count":         {count: 120, pageCap: 50, wantCount: 120, wantRequests: 4},
+		"empty":                       {count: 0, pageCap: 100, withTotal: true, wantCount: 0, wantRequests: 1},
+		"page ignored by the API":     {count: 120, pageCap: 50, ignorePage: true, wantCount: 50, wantRequests: 2},
+		"page ignored by the API with total count": {count: 120, pageCap: 50, withTotal: true, ignorePage: true, wantCount: 50, wantRequests: 2},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			server, requests := newListServer(t, test.count, test.pageCap, test.withTotal, test.ignorePage)
+			client := v1.NewClientV1WithCustomHTTP(server.Client(), "token", server.URL)
+
+			origins, _, err := l7origin.ListAll(context.Background(), client, &l7origin.ListOpts{L7ResourceID: 1})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(origins) != test.wantCount {
+				t.Errorf("got %d origins, want %d", len(origins), test.wantCount)
+			}
+			for i, origin := range origins {
+				if origin.ID != int64(i+1) {
+					t.Fatalf("got origin %d at %d, want %d", origin.ID, i, i+1)
+				}
+			}
+			if *requests != test.wantRequests {
+				t.Errorf("got %d requests, want %d", *requests, test.wantRequests)
+			}
+		})
+	}
+}
@@ -1,5 +1,20 @@
 package l7origin
 
+const (
+	// ModePrimary is the mode of an origin that receives traffic.
+	ModePrimary = "primary"
+
+	// ModeBackup is the mode of an origin that receives traffic only when
+	// primary origins are unavailable.
+	ModeBackup = "backup"
+
+	// MinWeight is the minimal weight of an origin accepted by the API.
+	MinWeight = 0
+
+	// MaxWeight is the maximal weight of an origin accepted by the API.
+	MaxWeight = 100
+)
+
 type Data struct {
 	Data Result `json:"data"`
 }
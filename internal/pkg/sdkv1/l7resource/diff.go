@@ -0,0 +1,145 @@
+package l7resource
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Change represents a changed setting of a domain.
+type Change struct {
+	// Field is the JSON name of the changed field.
+	Field string
+
+	// Old is the value before the change.
+	Old any
+
+	// New is the value after the change.
+	New any
+}
+
+// String returns a human readable representation of the change.
+func (c Change) String() string {
+	return fmt.Sprintf("%s: %v -> %v", c.Field, c.Old, c.New)
+}
+
+// ChangeSet represents changed settings of a domain in the order of Item fields.
+type ChangeSet []Change
+
+// IsEmpty reports whether there are no changes.
+func (cs ChangeSet) IsEmpty() bool {
+	return len(cs) == 0
+}
+
+// Fields returns JSON names of the changed fields.
+func (cs ChangeSet) Fields() []string {
+	fields := make([]string, 0, len(cs))
+	for _, change := range cs {
+		fields = append(fields, change.Field)
+	}
+
+	return fields
+}
+
+// UpdateOpts returns update options setting the new values of the changes.
+func (cs ChangeSet) UpdateOpts(l7ResourceID int64) *UpdateOpts {
+	opts := &UpdateOpts{L7ResourceID: l7ResourceID}
+	optsValue := reflect.ValueOf(opts).Elem()
+	optsFields := jsonFields(optsValue.Type())
+	for _, change := range cs {
+		i, ok := optsFields[change.Field]
+		if !ok {
+			continue
+		}
+
+		value := reflect.New(optsValue.Field(i).Type().Elem())
+		value.Elem().Set(reflect.ValueOf(change.New))
+		optsValue.Field(i).Set(value)
+	}
+
+	return opts
+}
+
+// Diff returns the settings that differ between old and new. Fields are
+// compared by their JSON names, fields tagged with diff:"-" are ignored. A nil
+// item is compared as an item with zero values.
+func Diff(old, new *Item) ChangeSet {
+	if old == nil {
+		old = &Item{}
+	}
+	if new == nil {
+		new = &Item{}
+	}
+
+	oldValue := reflect.ValueOf(old).Elem()
+	newValue := reflect.ValueOf(new).Elem()
+	itemType := oldValue.Type()
+
+	var changes ChangeSet
+	for i := 0; i < itemType.NumField(); i++ {
+		name := settingName(itemType.Field(i))
+		if name == "" {
+			continue
+		}
+
+		oldField := oldValue.Field(i).Interface()
+		newField := newValue.Field(i).Interface()
+		if !reflect.DeepEqual(oldField, newField) {
+			changes = append(changes, Change{Field: name, Old: oldField, New: newField})
+		}
+	}
+
+	return changes
+}
+
+// Settings returns every setting of the item as a change from an unknown value,
+// such as the settings of a domain whose current settings are not known.
+func Settings(item *Item) ChangeSet {
+	value := reflect.ValueOf(item).Elem()
+	itemType := value.Type()
+
+	var changes ChangeSet
+	for i := 0; i < itemType.NumField(); i++ {
+		if name := settingName(itemType.Field(i)); name != "" {
+			changes = append(changes, Change{Field: name, New: value.Field(i).Interface()})
+		}
+	}
+
+	return changes
+}
+
+// settingName returns the JSON name of a setting field, an empty string for
+// fields that are not settings.
+func settingName(field reflect.StructField) string {
+	if field.Tag.Get("diff") == "-" {
+		return ""
+	}
+
+	return jsonName(field)
+}
+
+// jsonFields returns indexes of the struct fields by their JSON names.
+func jsonFields(structType reflect.Type) map[string]int {
+	fields := make(map[string]int, structType.NumField())
+	for i := 0; i < structType.NumField(); i++ {
+		if name := jsonName(structType.Field(i)); name != "" {
+			fields[name] = i
+		}
+	}
+
+	return fields
+}
+
+// jsonName returns the JSON name of the struct field, an empty string for
+// fields that are not serialized.
+func jsonName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "-" || !field.IsExported() {
+		return ""
+	}
+	if name == "" {
+		return field.Name
+	}
+
+	return name
+}
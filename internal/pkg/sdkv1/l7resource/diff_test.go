@@ -0,0 +1,181 @@
+package l7resource_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"terraform-provider-servicepipe/internal/pkg/sdkv1/l7resource"
+)
+
+func TestDiff(t *testing.T) {
+	current := &l7resource.Item{
+		L7ResourceID:   1,
+		L7ResourceName: "example.com",
+		Forcessl:       0,
+		GeoipList:      "RU",
+		ModifiedAt:     1000,
+	}
+
+	tests := map[string]struct {
+		old, new *l7resource.Item
+		want     l7resource.ChangeSet
+	}{
+		"unchanged": {
+			old: current,
+			new: current,
+		},
+		"string field": {
+			old: current,
+			new: &l7resource.Item{L7ResourceID: 1, L7ResourceName: "example.com", GeoipList: "RU,BY", ModifiedAt: 1000},
+			want: l7resource.ChangeSet{
+				{Field: "geoipList", Old: "RU", New: "RU,BY"},
+			},
+		},
+		"int field": {
+			old: current,
+			new: &l7resource.Item{L7ResourceID: 1, L7ResourceName: "example.com", Forcessl: 1, GeoipList: "RU", ModifiedAt: 1000},
+			want: l7resource.ChangeSet{
+				{Field: "forcessl", Old: 0, New: 1},
+			},
+		},
+		"ignored fields": {
+			old: current,
+			new: &l7resource.Item{L7ResourceID: 2, L7ResourceName: "example.com", GeoipList: "RU", ModifiedAt: 2000},
+		},
+		"fields in item order": {
+			old: current,
+			new: &l7resource.Item{L7ResourceID: 1, L7ResourceName: "example.org", Forcessl: 1, GeoipList: "", ModifiedAt: 1000},
+			want: l7resource.ChangeSet{
+				{Field: "l7ResourceName", Old: "example.com", New: "example.org"},
+				{Field: "forcessl", Old: 0, New: 1},
+				{Field: "geoipList", Old: "RU", New: ""},
+			},
+		},
+		"nil old": {
+			new: current,
+			want: l7resource.ChangeSet{
+				{Field: "l7ResourceName", Old: "", New: "example.com"},
+				{Field: "geoipList", Old: "", New: "RU"},
+			},
+		},
+		"nil new": {
+			old: current,
+			want: l7resource.ChangeSet{
+				{Field: "l7ResourceName", Old: "example.com", New: ""},
+				{Field: "geoipList", Old: "RU", New: ""},
+			},
+		},
+		"nil old and new": {},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := l7resource.Diff(test.old, test.new)
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestChangeSetUpdateOpts(t *testing.T) {
+	current := &l7resource.Item{L7ResourceID: 1, L7ResourceName: "example.com", GeoipList: "RU"}
+	desired := &l7resource.Item{
+		L7ResourceID:          1,
+		L7ResourceName:        "example.org",
+		L7ResourceIsActive:    1,
+		L7ProtectionDisable:   1,
+		UseCustomSsl:          1,
+		UseLetsencryptSsl:     1,
+		CustomSslKey:          "key",
+		CustomSslCrt:          "crt",
+		Forcessl:              1,
+		ServiceHTTP2:          1,
+		GeoipMode:             2,
+		GeoipList:             "",
+		GlobalWhitelistActive: 1,
+		HTTP2https:            1,
+		HTTPS2http:            1,
+		Wwwredir:              1,
+		Cdn:                   1,
+		CdnHost:               "cdn.example.org",
+		CdnProxyHost:          "proxy.example.org",
+	}
+
+	changes := l7resource.Diff(current, desired)
+	opts := changes.UpdateOpts(1)
+
+	data, err := json.Marshal(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fields map[string]any
+	if err := json.Unmarshal(data, &fields); err != nil {
+		t.Fatal(err)
+	}
+
+	if fields["l7ResourceId"] != float64(1) {
+		t.Errorf("got l7ResourceId %v, want 1", fields["l7ResourceId"])
+	}
+	delete(fields, "l7ResourceId")
+
+	// Every changed setting round trips, including ones set to zero values.
+	if len(fields) != len(changes) {
+		t.Errorf("got update options %v, want the %d changed fields %v", fields, len(changes), changes.Fields())
+	}
+	for _, change := range changes {
+		value, ok := fields[change.Field]
+		if !ok {
+			t.Errorf("update options miss the changed field %s", change.Field)
+			continue
+		}
+
+		want, err := json.Marshal(change.New)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := json.Marshal(value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != string(want) {
+			t.Errorf("got %s of %s, want %s", got, change.Field, want)
+		}
+	}
+
+	if empty := l7resource.Diff(current, current).UpdateOpts(1); !empty.IsEmpty() {
+		t.Errorf("got update options %+v of no changes, want empty ones", empty)
+	}
+}
+
+func TestSettings(t *testing.T) {
+	item := &l7resource.Item{L7ResourceID: 1, L7ResourceName: "example.com", Forcessl: 1, ModifiedAt: 1000}
+
+	changes := l7resource.Settings(item)
+	if len(changes) != len(l7resource.Diff(nil, &l7resource.Item{
+		L7ResourceName: "a", L7ResourceIsActive: 1, L7ProtectionDisable: 1, UseCustomSsl: 1, UseLetsencryptSsl: 1,
+		CustomSslKey: "a", CustomSslCrt: "a", Forcessl: 1, ServiceHTTP2: 1, GeoipMode: 1, GeoipList: "a",
+		GlobalWhitelistActive: 1, HTTP2https: 1, HTTPS2http: 1, Wwwredir: 1, Cdn: 1, CdnHost: "a", CdnProxyHost: "a",
+	})) {
+		t.Errorf("got settings %v, want every setting", changes.Fields())
+	}
+
+	for _, change := range changes {
+		if change.Old != nil {
+			t.Errorf("got old value %v of %s, want none", change.Old, change.Field)
+		}
+		switch change.Field {
+		case "l7ResourceId", "modifiedAt":
+			t.Errorf("got %s, which is not a setting", change.Field)
+		case "l7ResourceName":
+			if change.New != "example.com" {
+				t.Errorf("got l7ResourceName %v, want example.com", change.New)
+			}
+		case "serviceHttp2":
+			if change.New != 0 {
+				t.Errorf("got serviceHttp2 %v, want the zero value", change.New)
+			}
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package l7resource
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/net/idna"
+)
+
+// NormalizeName returns the ASCII (punycode) form of a domain name the API
+// stores: lower case, without a trailing dot and a leading "www.".
+func NormalizeName(name string) (string, error) {
+	name = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(name)), ".")
+	name = strings.TrimPrefix(name, "www.")
+	if name == "" {
+		return "", fmt.Errorf("sp-go: l7ResourceName must be not empty")
+	}
+
+	ascii, err := idna.Lookup.ToASCII(name)
+	if err != nil {
+		return "", fmt.Errorf("sp-go: invalid domain name %q: %w", name, err)
+	}
+
+	return ascii, nil
+}
+
+// UnicodeName returns the Unicode form of a domain name.
+func UnicodeName(name string) (string, error) {
+	ascii, err := NormalizeName(name)
+	if err != nil {
+		return "", err
+	}
+
+	unicode, err := idna.Lookup.ToUnicode(ascii)
+	if err != nil {
+		return "", fmt.Errorf("sp-go: invalid domain name %q: %w", name, err)
+	}
+
+	return unicode, nil
+}
+
+// NamesEqual reports whether domain names are the same once normalized, so the
+// Unicode and punycode forms of a name are equal.
+func NamesEqual(a, b string) bool {
+	if a == b {
+		return true
+	}
+
+	normalizedA, err := NormalizeName(a)
+	if err != nil {
+		return false
+	}
+	normalizedB, err := NormalizeName(b)
+	if err != nil {
+		return false
+	}
+
+	return normalizedA == normalizedB
+}
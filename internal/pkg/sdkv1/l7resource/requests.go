@@ -4,16 +4,26 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
 	"strings"
 
 	v1 "terraform-provider-servicepipe/internal/pkg/sdkv1"
+
+	"github.com/google/go-querystring/query"
 )
 
 const l7ResourcePath = "l7/resource"
 
+// defaultListLimit is the page size used by ListAll.
+const defaultListLimit = 100
+
+// ErrConcurrentModification is returned by Patch when the domain was modified
+// after the time the update expects.
+var ErrConcurrentModification = errors.New("sp-go: l7 resource was modified concurrently")
+
 // GetByID returns a single resource by its id.
 func GetByID(ctx context.Context, client *v1.Client, l7ResourceID int) (*Data, *v1.ResponseResult, error) {
 	url := strings.Join([]string{client.Endpoint, l7ResourcePath, strconv.Itoa(l7ResourceID)}, "/")
@@ -35,9 +45,69 @@ func GetByID(ctx context.Context, client *v1.Client, l7ResourceID int) (*Data, *
 	return l7Resource, responseResult, nil
 }
 
-// List gets a list of all domains.
+// List gets a list of domains, the API returns only the first page of the
+// list. Use ListAll to get every domain.
 func List(ctx context.Context, client *v1.Client) ([]*Item, *v1.ResponseResult, error) {
+	items, responseResult, err := list(ctx, client, nil)
+	if err != nil {
+		return nil, responseResult, err
+	}
+
+	return convertToSliceOfPointers(items.Items), responseResult, nil
+}
+
+// ListAll gets domains of every page of the list. Opts page is ignored, opts
+// limit is used as the page size. Pages are requested until the total count of
+// the list is reached, or until an empty page when the API doesn't return it.
+// A page without new domains also ends the list, so an API ignoring the page
+// doesn't make it loop.
+func ListAll(ctx context.Context, client *v1.Client, opts *ListOpts) ([]*Item, *v1.ResponseResult, error) {
+	pageOpts := ListOpts{}
+	if opts != nil {
+		pageOpts = *opts
+	}
+	if pageOpts.Limit <= 0 {
+		pageOpts.Limit = defaultListLimit
+	}
+	pageOpts.Page = 1
+
+	var domains []*Item
+	seen := make(map[int64]bool)
+	for {
+		items, responseResult, err := list(ctx, client, &pageOpts)
+		if err != nil {
+			return nil, responseResult, err
+		}
+
+		added := 0
+		for _, item := range convertToSliceOfPointers(items.Items) {
+			if seen[item.L7ResourceID] {
+				continue
+			}
+			seen[item.L7ResourceID] = true
+			domains = append(domains, item)
+			added++
+		}
+
+		if added == 0 || items.Info.TotalCount > 0 && int64(len(domains)) >= items.Info.TotalCount {
+			return domains, responseResult, nil
+		}
+
+		pageOpts.Page++
+	}
+}
+
+// list gets a single page of domains, nil opts get the default page.
+func list(ctx context.Context, client *v1.Client, opts *ListOpts) (*Items, *v1.ResponseResult, error) {
 	url := strings.Join([]string{client.Endpoint, l7ResourcePath}, "/")
+	if opts != nil {
+		queryParams, err := query.Values(opts)
+		if err != nil {
+			return nil, nil, err
+		}
+		url = strings.Join([]string{url, queryParams.Encode()}, "?")
+	}
+
 	responseResult, err := client.DoRequest(ctx, http.MethodGet, url, nil)
 	if err != nil {
 		return nil, nil, err
@@ -52,13 +122,34 @@ func List(ctx context.Context, client *v1.Client) ([]*Item, *v1.ResponseResult,
 	if err != nil {
 		return nil, responseResult, err
 	}
-	convertedItems := convertToSliceOfPointers(dataitems.DataItems.ResultItems.Items)
-	return convertedItems, responseResult, nil
+
+	return &dataitems.DataItems.ResultItems, responseResult, nil
 }
 
 // Create requests a creation of a new domain.
 func Create(ctx context.Context, client *v1.Client, opts *CreateOpts) (*Data, *v1.ResponseResult, error) {
+	domain, responseResult, err := create(ctx, client, opts)
+
+	event := &v1.AuditEvent{
+		Operation: "l7resource.Create",
+		Method:    http.MethodPost,
+		TargetIDs: map[string]int64{},
+		Changes:   v1.AuditDiff(nil, opts),
+	}
+	if domain != nil {
+		event.TargetIDs["l7ResourceId"] = domain.Data.Result.L7ResourceID
+	}
+	client.Audit(ctx, event, responseResult, err)
+
+	return domain, responseResult, err
+}
+
+func create(ctx context.Context, client *v1.Client, opts *CreateOpts) (*Data, *v1.ResponseResult, error) {
 	url := strings.Join([]string{client.Endpoint, l7ResourcePath}, "/")
+	if err := opts.Normalize(); err != nil {
+		return nil, nil, err
+	}
+
 	requestBody, err := json.Marshal(opts)
 	if err != nil {
 		return nil, nil, err
@@ -88,6 +179,19 @@ func Create(ctx context.Context, client *v1.Client, opts *CreateOpts) (*Data, *v
 
 // Delete deletes a single domain by its id.
 func Delete(ctx context.Context, client *v1.Client, opts *DeleteOpts) (*DataDelete, *v1.ResponseResult, error) {
+	defer client.LockResource(int64(opts.L7ResourceID))()
+
+	result, responseResult, err := deleteDomain(ctx, client, opts)
+	client.Audit(ctx, &v1.AuditEvent{
+		Operation: "l7resource.Delete",
+		Method:    http.MethodDelete,
+		TargetIDs: map[string]int64{"l7ResourceId": int64(opts.L7ResourceID)},
+	}, responseResult, err)
+
+	return result, responseResult, err
+}
+
+func deleteDomain(ctx context.Context, client *v1.Client, opts *DeleteOpts) (*DataDelete, *v1.ResponseResult, error) {
 	url := strings.Join([]string{client.Endpoint, l7ResourcePath}, "/")
 	requestBody, err := json.Marshal(opts)
 	if err != nil {
@@ -112,10 +216,70 @@ func Delete(ctx context.Context, client *v1.Client, opts *DeleteOpts) (*DataDele
 	return result, responseResult, nil
 }
 
-// Update deletes a single domain by its id.
+// Update replaces a single domain with the item.
 func Update(ctx context.Context, client *v1.Client, item *Item) (*Data, *v1.ResponseResult, error) {
+	return UpdateFrom(ctx, client, nil, item)
+}
+
+// UpdateFrom replaces a single domain with the item. Previous is the domain
+// before the update, it is only used to record the changed fields when the
+// client records mutating calls, a nil previous records every field.
+func UpdateFrom(ctx context.Context, client *v1.Client, previous, item *Item) (*Data, *v1.ResponseResult, error) {
+	defer client.LockResource(item.L7ResourceID)()
+
+	result, responseResult, err := update(ctx, client, item)
+	client.Audit(ctx, &v1.AuditEvent{
+		Operation: "l7resource.Update",
+		Method:    http.MethodPut,
+		TargetIDs: map[string]int64{"l7ResourceId": item.L7ResourceID},
+		Changes:   v1.AuditDiff(previous, item),
+	}, responseResult, err)
+
+	return result, responseResult, err
+}
+
+// Patch fetches a single domain, applies the fields set in opts and updates the
+// domain with the result, so fields that are not set keep their current values.
+// Mutations of the domain made with the client are serialized.
+func Patch(ctx context.Context, client *v1.Client, opts *UpdateOpts) (*Data, *v1.ResponseResult, error) {
+	defer client.LockResource(opts.L7ResourceID)()
+
+	event := &v1.AuditEvent{
+		Operation: "l7resource.Update",
+		Method:    http.MethodPut,
+		TargetIDs: map[string]int64{"l7ResourceId": opts.L7ResourceID},
+	}
+	result, responseResult, err := patch(ctx, client, opts, event)
+	client.Audit(ctx, event, responseResult, err)
+
+	return result, responseResult, err
+}
+
+// patch applies opts to the current domain and records the changed fields in
+// the audit event.
+func patch(ctx context.Context, client *v1.Client, opts *UpdateOpts, event *v1.AuditEvent) (*Data, *v1.ResponseResult, error) {
+	current, responseResult, err := GetByID(ctx, client, int(opts.L7ResourceID))
+	if err != nil {
+		return nil, responseResult, err
+	}
+
+	if opts.IfModifiedAt != 0 && current.Data.Result.ModifiedAt != opts.IfModifiedAt {
+		return nil, responseResult, fmt.Errorf("%w: l7ResourceId %d has modifiedAt %d, expected %d",
+			ErrConcurrentModification, opts.L7ResourceID, current.Data.Result.ModifiedAt, opts.IfModifiedAt)
+	}
+
+	body := newUpdateOpts(&current.Data.Result)
+	body.apply(opts)
+	event.Changes = v1.AuditDiff(&current.Data.Result, body)
+
+	return update(ctx, client, body)
+}
+
+// update sends the body to update a single domain, the caller holds the lock of
+// the domain.
+func update(ctx context.Context, client *v1.Client, body any) (*Data, *v1.ResponseResult, error) {
 	url := strings.Join([]string{client.Endpoint, l7ResourcePath}, "/")
-	requestBody, err := json.Marshal(item)
+	requestBody, err := json.Marshal(body)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -1,5 +1,7 @@
 package l7resource
 
+import "reflect"
+
 // CreateOpts represents requests options to create a domain.
 type CreateOpts struct {
 	// L7ResourceName represents valid domain name (without www.).
@@ -10,7 +12,112 @@ type CreateOpts struct {
 	Wwwredir   int    `json:"wwwredir,omitempty"`
 }
 
-// CreateOpts represents requests options to create a domain.
+// Normalize converts L7ResourceName into the ASCII form the API stores.
+func (opts *CreateOpts) Normalize() error {
+	name, err := NormalizeName(opts.L7ResourceName)
+	if err != nil {
+		return err
+	}
+	opts.L7ResourceName = name
+
+	return nil
+}
+
+// UpdateOpts represents requests options to update a domain. Only fields that
+// are set are changed.
+type UpdateOpts struct {
+	// L7ResourceID is the identifier of the resource.
+	L7ResourceID int64 `json:"l7ResourceId"`
+
+	// IfModifiedAt makes Patch fail with ErrConcurrentModification when the
+	// domain modification time is different, zero skips the check.
+	IfModifiedAt int `json:"-"`
+
+	L7ResourceName        *string `json:"l7ResourceName,omitempty"`
+	L7ResourceIsActive    *int    `json:"l7ResourceIsActive,omitempty"`
+	L7ProtectionDisable   *int    `json:"l7ProtectionDisable,omitempty"`
+	UseCustomSsl          *int    `json:"useCustomSsl,omitempty"`
+	UseLetsencryptSsl     *int    `json:"useLetsencryptSsl,omitempty"`
+	CustomSslKey          *string `json:"customSslKey,omitempty"`
+	CustomSslCrt          *string `json:"customSslCrt,omitempty"`
+	Forcessl              *int    `json:"forcessl,omitempty"`
+	ServiceHTTP2          *int    `json:"serviceHttp2,omitempty"`
+	GeoipMode             *int    `json:"geoipMode,omitempty"`
+	GeoipList             *string `json:"geoipList,omitempty"`
+	GlobalWhitelistActive *int    `json:"globalWhitelistActive,omitempty"`
+	HTTP2https            *int    `json:"http2https,omitempty"`
+	HTTPS2http            *int    `json:"https2http,omitempty"`
+	ProtectedIp           *string `json:"protectedIp,omitempty"`
+	Wwwredir              *int    `json:"wwwredir,omitempty"`
+	Cdn                   *int    `json:"cdn,omitempty"`
+	CdnHost               *string `json:"cdnHost,omitempty"`
+	CdnProxyHost          *string `json:"cdnProxyHost,omitempty"`
+}
+
+// IsEmpty reports whether no field is set.
+func (opts *UpdateOpts) IsEmpty() bool {
+	value := reflect.ValueOf(opts).Elem()
+	for i := 0; i < value.NumField(); i++ {
+		if value.Field(i).Kind() == reflect.Pointer && !value.Field(i).IsNil() {
+			return false
+		}
+	}
+
+	return true
+}
+
+// apply sets fields that are set in changes.
+func (opts *UpdateOpts) apply(changes *UpdateOpts) {
+	optsValue := reflect.ValueOf(opts).Elem()
+	changesValue := reflect.ValueOf(changes).Elem()
+	for i := 0; i < changesValue.NumField(); i++ {
+		if changesValue.Field(i).Kind() == reflect.Pointer && !changesValue.Field(i).IsNil() {
+			optsValue.Field(i).Set(changesValue.Field(i))
+		}
+	}
+}
+
+// newUpdateOpts returns update options setting every field of the item. SSL
+// certificates are not returned by the API, they are set only when not empty.
+func newUpdateOpts(item *Item) *UpdateOpts {
+	opts := &UpdateOpts{
+		L7ResourceID:          item.L7ResourceID,
+		L7ResourceName:        &item.L7ResourceName,
+		L7ResourceIsActive:    &item.L7ResourceIsActive,
+		L7ProtectionDisable:   &item.L7ProtectionDisable,
+		UseCustomSsl:          &item.UseCustomSsl,
+		UseLetsencryptSsl:     &item.UseLetsencryptSsl,
+		Forcessl:              &item.Forcessl,
+		ServiceHTTP2:          &item.ServiceHTTP2,
+		GeoipMode:             &item.GeoipMode,
+		GeoipList:             &item.GeoipList,
+		GlobalWhitelistActive: &item.GlobalWhitelistActive,
+		HTTP2https:            &item.HTTP2https,
+		HTTPS2http:            &item.HTTPS2http,
+		ProtectedIp:           &item.ProtectedIp,
+		Wwwredir:              &item.Wwwredir,
+		Cdn:                   &item.Cdn,
+		CdnHost:               &item.CdnHost,
+		CdnProxyHost:          &item.CdnProxyHost,
+	}
+	if item.CustomSslKey != "" {
+		opts.CustomSslKey = &item.CustomSslKey
+	}
+	if item.CustomSslCrt != "" {
+		opts.CustomSslCrt = &item.CustomSslCrt
+	}
+
+	return opts
+}
+
+// ListOpts represents requests options to list domains.
+type ListOpts struct {
+	Sort  string `url:"sort,omitempty"`
+	Page  int64  `url:"page,omitempty"`
+	Limit int64  `url:"limit,omitempty"`
+}
+
+// DeleteOpts represents requests options to delete a domain.
 type DeleteOpts struct {
 	// L7ResourceID is the identifier of the resource.
 	L7ResourceID int `json:"l7ResourceId,omitempty"`
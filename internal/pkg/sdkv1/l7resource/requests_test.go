@@ -0,0 +1,193 @@
+package l7resource_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	v1 "terraform-provider-servicepipe/internal/pkg/sdkv1"
+	"terraform-provider-servicepipe/internal/pkg/sdkv1/l7resource"
+)
+
+func TestListAll(t *testing.T) {
+	tests := map[string]struct {
+		count        int
+		pageCap      int
+		withTotal    bool
+		ignorePage   bool
+		wantCount    int
+		wantRequests int
+	}{
+		"single page":                 {count: 3, pageCap: 100, withTotal: true, wantCount: 3, wantRequests: 1},
+		"several pages":               {count: 250, pageCap: 100, withTotal: true, wantCount: 250, wantRequests: 3},
+		"page size capped by the API": {count: 120, pageCap: 50, withTotal: true, wantCount: 120, wantRequests: 3},
+		"without total count":         {count: 120, pageCap: 50, wantCount: 120, wantRequests: 4},
+		"page ignored by the API":     {count: 20, pageCap: 100, ignorePage: true, wantCount: 20, wantRequests: 2},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			requests := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests++
+
+				page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+				limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+				if limit > test.pageCap {
+					limit = test.pageCap
+				}
+				if test.ignorePage {
+					page = 1
+				}
+
+				body := l7resource.DataItems{}
+				body.DataItems.ResultItems.Items = []l7resource.Item{}
+				for id := (page-1)*limit + 1; id <= page*limit && id <= test.count; id++ {
+					body.DataItems.ResultItems.Items = append(body.DataItems.ResultItems.Items, l7resource.Item{
+						L7ResourceID:   int64(id),
+						L7ResourceName: "domain" + strconv.Itoa(id) + ".example",
+					})
+				}
+				if test.withTotal {
+					body.DataItems.ResultItems.Info.TotalCount = int64(test.count)
+				}
+				if err := json.NewEncoder(w).Encode(body); err != nil {
+					t.Error(err)
+				}
+			}))
+			defer server.Close()
+
+			client := v1.NewClientV1WithCustomHTTP(server.Client(), "token", server.URL)
+			items, _, err := l7resource.ListAll(context.Background(), client, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(items) != test.wantCount {
+				t.Errorf("got %d domains, want %d", len(items), test.wantCount)
+			}
+			if requests != test.wantRequests {
+				t.Errorf("got %d requests, want %d", requests, test.wantRequests)
+			}
+		})
+	}
+}
+
+// newDomainServer returns a server of a single domain and the number of PUT
+// requests it got. Reads are slow, so updates made from stale reads overwrite
+// each other.
+func newDomainServer(t *testing.T, domain *l7resource.Item) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+
+	var mu sync.Mutex
+	var puts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		switch r.Method {
+		case http.MethodGet:
+			result := *domain
+			mu.Unlock()
+			time.Sleep(2 * time.Millisecond)
+			if err := json.NewEncoder(w).Encode(l7resource.Data{Data: l7resource.Result{Result: result}}); err != nil {
+				t.Error(err)
+			}
+		case http.MethodPut:
+			defer mu.Unlock()
+			puts.Add(1)
+			updated := l7resource.Item{ModifiedAt: domain.ModifiedAt + 1}
+			if err := json.NewDecoder(r.Body).Decode(&updated); err != nil {
+				t.Error(err)
+			}
+			*domain = updated
+			if err := json.NewEncoder(w).Encode(l7resource.Data{Data: l7resource.Result{Result: updated}}); err != nil {
+				t.Error(err)
+			}
+		default:
+			mu.Unlock()
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return server, &puts
+}
+
+func TestPatchSerialized(t *testing.T) {
+	domain := &l7resource.Item{L7ResourceID: 1, L7ResourceName: "example.com", ModifiedAt: 1000}
+	server, puts := newDomainServer(t, domain)
+	client := v1.NewClientV1WithCustomHTTP(server.Client(), "token", server.URL)
+
+	one := 1
+	changes := []*l7resource.UpdateOpts{
+		{L7ResourceID: 1, Forcessl: &one},
+		{L7ResourceID: 1, ServiceHTTP2: &one},
+		{L7ResourceID: 1, HTTP2https: &one},
+		{L7ResourceID: 1, Wwwredir: &one},
+		{L7ResourceID: 1, Cdn: &one},
+	}
+
+	// Concurrent patches of the domain each read it after the previous one
+	// was written, so no change is lost.
+	var wg sync.WaitGroup
+	for _, opts := range changes {
+		wg.Add(1)
+		go func(opts *l7resource.UpdateOpts) {
+			defer wg.Done()
+			if _, _, err := l7resource.Patch(context.Background(), client, opts); err != nil {
+				t.Error(err)
+			}
+		}(opts)
+	}
+	wg.Wait()
+
+	if got := puts.Load(); got != int32(len(changes)) {
+		t.Errorf("got %d updates, want %d", got, len(changes))
+	}
+	if domain.Forcessl != 1 || domain.ServiceHTTP2 != 1 || domain.HTTP2https != 1 || domain.Wwwredir != 1 || domain.Cdn != 1 {
+		t.Errorf("got domain %+v, want every patched field set", domain)
+	}
+	if domain.L7ResourceName != "example.com" {
+		t.Errorf("got name %q, want the name kept", domain.L7ResourceName)
+	}
+}
+
+func TestPatchIfModifiedAt(t *testing.T) {
+	tests := map[string]struct {
+		ifModifiedAt int
+		wantConflict bool
+	}{
+		"not checked":      {ifModifiedAt: 0},
+		"unmodified":       {ifModifiedAt: 1000},
+		"modified":         {ifModifiedAt: 999, wantConflict: true},
+		"modified earlier": {ifModifiedAt: 1001, wantConflict: true},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			domain := &l7resource.Item{L7ResourceID: 1, L7ResourceName: "example.com", ModifiedAt: 1000}
+			server, puts := newDomainServer(t, domain)
+			client := v1.NewClientV1WithCustomHTTP(server.Client(), "token", server.URL)
+
+			one := 1
+			_, _, err := l7resource.Patch(context.Background(), client, &l7resource.UpdateOpts{L7ResourceID: 1, IfModifiedAt: test.ifModifiedAt, Forcessl: &one})
+			if got := errors.Is(err, l7resource.ErrConcurrentModification); got != test.wantConflict {
+				t.Fatalf("got error %v, want conflict %t", err, test.wantConflict)
+			}
+
+			wantPuts := int32(1)
+			if test.wantConflict {
+				wantPuts = 0
+			}
+			if got := puts.Load(); got != wantPuts {
+				t.Errorf("got %d updates, want %d", got, wantPuts)
+			}
+		})
+	}
+}
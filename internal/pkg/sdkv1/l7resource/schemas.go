@@ -1,5 +1,16 @@
 package l7resource
 
+const (
+	// GeoipModeOff disables GeoIP filtering.
+	GeoipModeOff = 0
+
+	// GeoipModeAllow allows access only from countries of GeoipList.
+	GeoipModeAllow = 1
+
+	// GeoipModeDeny denies access from countries of GeoipList.
+	GeoipModeDeny = 2
+)
+
 type Data struct {
 	Data Result `json:"data"`
 }
@@ -8,13 +19,14 @@ type Result struct {
 	Result Item `json:"result"`
 }
 
-// Item represents an unmarshalled domain body from API response.
+// Item represents an unmarshalled domain body from API response. Fields tagged
+// with diff:"-" are not settings of the domain and are ignored by Diff.
 type Item struct {
 	// PartnerClientAccountID is the identifier of partner client.
-	PartnerClientAccountID int `json:"partnerClientAccountId"`
+	PartnerClientAccountID int `json:"partnerClientAccountId" diff:"-"`
 
 	// L7ResourceID is the identifier of the resource.
-	L7ResourceID        int64  `json:"l7ResourceId"`
+	L7ResourceID        int64  `json:"l7ResourceId" diff:"-"`
 	L7ResourceName      string `json:"l7ResourceName"`
 	L7ResourceIsActive  int    `json:"l7ResourceIsActive"`
 	L7ProtectionDisable int    `json:"l7ProtectionDisable"`
@@ -24,13 +36,13 @@ type Item struct {
 	CustomSslCrt        string `json:"customSslCrt"`
 
 	// CreateDate represents Unix timestamp when resource has been created.
-	DeletedAt string `json:"deletedAt"`
-	Sort      string `json:"sort"`
-	Page      int    `json:"page"`
-	Limit     int    `json:"limit"`
+	DeletedAt string `json:"deletedAt" diff:"-"`
+	Sort      string `json:"sort" diff:"-"`
+	Page      int    `json:"page" diff:"-"`
+	Limit     int    `json:"limit" diff:"-"`
 
 	// CreateDate represents Unix timestamp when domain has been created.
-	СreatedAt             int    `json:"сreatedAt"`
+	СreatedAt             int    `json:"сreatedAt" diff:"-"`
 	Forcessl              int    `json:"forcessl"`
 	ServiceHTTP2          int    `json:"serviceHttp2"`
 	GeoipMode             int    `json:"geoipMode"`
@@ -40,14 +52,14 @@ type Item struct {
 	HTTP2https int `json:"http2https"`
 	HTTPS2http int `json:"https2http"`
 
-	ProtectedIp   string `json:"protectedIp"`
-	ModifiedAt    int    `json:"modifiedAt"`
-	SslExpireDate int    `json:"SslExpireDate"`
+	ProtectedIp   string `json:"protectedIp" diff:"-"`
+	ModifiedAt    int    `json:"modifiedAt" diff:"-"`
+	SslExpireDate int    `json:"SslExpireDate" diff:"-"`
 	Wwwredir      int    `json:"wwwredir"`
 	Cdn           int    `json:"cdn"`
 	CdnHost       string `json:"cdnHost"`
 	CdnProxyHost  string `json:"cdnProxyHost"`
-	OriginData    string `json:"originData,omitempty"`
+	OriginData    string `json:"originData,omitempty" diff:"-"`
 }
 
 type DataItems struct {
@@ -60,6 +72,13 @@ type ResultItems struct {
 
 type Items struct {
 	Items []Item `json:"items"`
+	Info  Info   `json:"info"`
+}
+
+type Info struct {
+	TotalCount int64 `json:"totalCount"`
+	Limit      int64 `json:"limit"`
+	Page       int64 `json:"page"`
 }
 
 type DataDelete struct {
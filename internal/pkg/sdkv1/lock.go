@@ -0,0 +1,49 @@
+package sdkv1
+
+import "sync"
+
+// keyedMutex serializes work per key, locks of keys nobody waits for are
+// released.
+type keyedMutex struct {
+	mu    sync.Mutex
+	locks map[int64]*keyedLock
+}
+
+type keyedLock struct {
+	mu   sync.Mutex
+	refs int
+}
+
+// lock locks the key and returns the function unlocking it.
+func (m *keyedMutex) lock(key int64) func() {
+	m.mu.Lock()
+	if m.locks == nil {
+		m.locks = make(map[int64]*keyedLock)
+	}
+	l, ok := m.locks[key]
+	if !ok {
+		l = &keyedLock{}
+		m.locks[key] = l
+	}
+	l.refs++
+	m.mu.Unlock()
+
+	l.mu.Lock()
+
+	return func() {
+		l.mu.Unlock()
+
+		m.mu.Lock()
+		l.refs--
+		if l.refs == 0 {
+			delete(m.locks, key)
+		}
+		m.mu.Unlock()
+	}
+}
+
+// LockResource serializes mutations of the l7 resource made with the client. It
+// returns the function releasing the lock.
+func (client *Client) LockResource(l7ResourceID int64) func() {
+	return client.resourceLocks.lock(l7ResourceID)
+}
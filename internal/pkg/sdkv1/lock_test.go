@@ -0,0 +1,72 @@
+package sdkv1_test
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	v1 "terraform-provider-servicepipe/internal/pkg/sdkv1"
+)
+
+func TestLockResource(t *testing.T) {
+	client := v1.NewClientV1WithCustomHTTP(nil, "token", "http://localhost")
+
+	t.Run("same resource", func(t *testing.T) {
+		var active, maxActive atomic.Int32
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				defer client.LockResource(1)()
+
+				current := active.Add(1)
+				if current > maxActive.Load() {
+					maxActive.Store(current)
+				}
+				time.Sleep(time.Millisecond)
+				active.Add(-1)
+			}()
+		}
+		wg.Wait()
+
+		if got := maxActive.Load(); got != 1 {
+			t.Errorf("got %d holders of the lock at the same time, want 1", got)
+		}
+	})
+
+	t.Run("other resources", func(t *testing.T) {
+		unlock := client.LockResource(1)
+		defer unlock()
+
+		locked := make(chan struct{})
+		go func() {
+			client.LockResource(2)()
+			close(locked)
+		}()
+
+		select {
+		case <-locked:
+		case <-time.After(time.Second):
+			t.Fatal("the lock of resource 2 waits for resource 1")
+		}
+	})
+
+	t.Run("released", func(t *testing.T) {
+		client.LockResource(3)()
+
+		// A released lock is taken again without waiting.
+		locked := make(chan struct{})
+		go func() {
+			client.LockResource(3)()
+			close(locked)
+		}()
+
+		select {
+		case <-locked:
+		case <-time.After(time.Second):
+			t.Fatal("the released lock of resource 3 is still held")
+		}
+	})
+}
@@ -0,0 +1,203 @@
+package recorder
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+)
+
+// CassetteVersion is the version of cassettes written by the recorder.
+const CassetteVersion = 1
+
+// scrubbedValue replaces values of scrubbed fields.
+const scrubbedValue = "REDACTED"
+
+// ScrubFields contains JSON fields whose values are never written to
+// cassettes, such as SSL material.
+var ScrubFields = map[string]bool{
+	"customSslKey": true,
+	"customSslCrt": true,
+	"token":        true,
+}
+
+// ScrubHeaders contains headers that are never written to cassettes.
+var ScrubHeaders = map[string]bool{
+	"Authorization": true,
+	"Cookie":        true,
+	"Set-Cookie":    true,
+}
+
+// Cassette represents recorded API interactions.
+type Cassette struct {
+	Version      int            `json:"version"`
+	Interactions []*Interaction `json:"interactions"`
+}
+
+// Interaction represents a single request and its response.
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+
+	// used is set when the interaction has been replayed.
+	used bool
+}
+
+// Request represents a recorded request, it is matched by the method, the
+// path with the sorted query and the normalized JSON body.
+type Request struct {
+	Method string `json:"method"`
+	Path   string `json:"path"`
+	Body   Body   `json:"body"`
+}
+
+// Response represents a recorded response.
+type Response struct {
+	StatusCode int         `json:"statusCode"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       Body        `json:"body"`
+}
+
+// Body is a recorded body. JSON bodies are kept as JSON with scrubbed fields,
+// so cassettes stay readable, other bodies as text.
+type Body struct {
+	JSON json.RawMessage `json:"json,omitempty"`
+	Text string          `json:"text,omitempty"`
+}
+
+// newBody returns the scrubbed and normalized body.
+func newBody(data []byte) Body {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return Body{}
+	}
+
+	var value any
+	if err := json.Unmarshal(data, &value); err != nil {
+		return Body{Text: string(data)}
+	}
+
+	normalized, err := json.Marshal(scrub(value))
+	if err != nil {
+		return Body{Text: string(data)}
+	}
+
+	return Body{JSON: normalized}
+}
+
+// Bytes returns the body as it is sent.
+func (b Body) Bytes() []byte {
+	if len(b.JSON) > 0 {
+		return b.JSON
+	}
+
+	return []byte(b.Text)
+}
+
+// equal reports whether the normalized bodies are equal.
+func (b Body) equal(other Body) bool {
+	if len(b.JSON) > 0 || len(other.JSON) > 0 {
+		return bytes.Equal(newBody(b.JSON).JSON, newBody(other.JSON).JSON)
+	}
+
+	return b.Text == other.Text
+}
+
+// scrub replaces values of ScrubFields in the decoded JSON value.
+func scrub(value any) any {
+	switch value := value.(type) {
+	case map[string]any:
+		for key, element := range value {
+			if ScrubFields[key] {
+				if element != nil && element != "" {
+					value[key] = scrubbedValue
+				}
+				continue
+			}
+			value[key] = scrub(element)
+		}
+	case []any:
+		for i, element := range value {
+			value[i] = scrub(element)
+		}
+	}
+
+	return value
+}
+
+// scrubHeader returns a copy of the header without ScrubHeaders.
+func scrubHeader(header http.Header) http.Header {
+	scrubbed := make(http.Header, len(header))
+	for key, values := range header {
+		if ScrubHeaders[http.CanonicalHeaderKey(key)] {
+			continue
+		}
+		scrubbed[key] = append([]string(nil), values...)
+	}
+
+	return scrubbed
+}
+
+// requestPath returns the path of the URL with the sorted query, the scheme
+// and the host are not recorded so cassettes work with any endpoint.
+func requestPath(u *url.URL) string {
+	if u.RawQuery == "" {
+		return u.Path
+	}
+
+	return u.Path + "?" + u.Query().Encode()
+}
+
+// matches reports whether the recorded request matches the request.
+func (r *Request) matches(other *Request) bool {
+	return r.Method == other.Method && r.Path == other.Path && r.Body.equal(other.Body)
+}
+
+// loadCassette reads the cassette file.
+func loadCassette(path string) (*Cassette, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	cassette := &Cassette{}
+	if err := json.Unmarshal(data, cassette); err != nil {
+		return nil, fmt.Errorf("sp-go: could not read cassette %s: %w", path, err)
+	}
+	if cassette.Version != CassetteVersion {
+		return nil, fmt.Errorf("sp-go: unsupported cassette version %d of %s", cassette.Version, path)
+	}
+
+	return cassette, nil
+}
+
+// save writes the cassette file through a temporary file, so an existing
+// cassette is never left half written.
+func (c *Cassette) save(path string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
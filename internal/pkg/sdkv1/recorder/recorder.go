@@ -0,0 +1,205 @@
+package recorder
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+)
+
+// Mode is the mode of a Recorder.
+type Mode int
+
+const (
+	// ModeReplay replays interactions of the cassette and fails requests that
+	// weren't recorded, no request reaches the network.
+	ModeReplay Mode = iota
+
+	// ModeRecord sends requests with the real transport and records them, the
+	// cassette is written by Stop.
+	ModeRecord
+)
+
+// ErrInteractionNotFound is returned by a replaying Recorder when the cassette
+// has no unused interaction matching the request.
+var ErrInteractionNotFound = errors.New("sp-go: recorder: no recorded interaction matches the request")
+
+// Recorder is a http.RoundTripper that records API interactions to a cassette
+// file or replays them from it. Use it with sdkv1.NewClientV1WithCustomHTTP:
+//
+//	rec, err := recorder.New("testdata/l7resource.json", recorder.ModeReplay, nil)
+//	client := v1.NewClientV1WithCustomHTTP(rec.HTTPClient(), token, endpoint)
+//	defer rec.Stop()
+type Recorder struct {
+	path      string
+	mode      Mode
+	transport http.RoundTripper
+
+	mu       sync.Mutex
+	cassette *Cassette
+}
+
+var _ http.RoundTripper = &Recorder{}
+
+// New returns a recorder of the cassette file. Recording sends requests with
+// transport, http.DefaultTransport is used when it is nil. Replaying loads the
+// cassette, recording starts an empty one.
+func New(path string, mode Mode, transport http.RoundTripper) (*Recorder, error) {
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	r := &Recorder{
+		path:      path,
+		mode:      mode,
+		transport: transport,
+		cassette:  &Cassette{Version: CassetteVersion},
+	}
+
+	switch mode {
+	case ModeReplay:
+		cassette, err := loadCassette(path)
+		if err != nil {
+			return nil, err
+		}
+		r.cassette = cassette
+	case ModeRecord:
+	default:
+		return nil, fmt.Errorf("sp-go: recorder: unknown mode %d", mode)
+	}
+
+	return r, nil
+}
+
+// NewFromEnv returns a recorder that records when the environment variable is
+// set to "record" and replays otherwise.
+func NewFromEnv(path, env string, transport http.RoundTripper) (*Recorder, error) {
+	mode := ModeReplay
+	if os.Getenv(env) == "record" {
+		mode = ModeRecord
+	}
+
+	return New(path, mode, transport)
+}
+
+// HTTPClient returns a HTTP client that uses the recorder.
+func (r *Recorder) HTTPClient() *http.Client {
+	return &http.Client{Transport: r}
+}
+
+// RoundTrip records or replays a single request.
+func (r *Recorder) RoundTrip(request *http.Request) (*http.Response, error) {
+	body, err := readBody(request.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	recorded := Request{
+		Method: request.Method,
+		Path:   requestPath(request.URL),
+		Body:   newBody(body),
+	}
+
+	if r.mode == ModeReplay {
+		return r.replay(request, &recorded)
+	}
+
+	// The body of the request has been read, the request is sent with a copy.
+	outgoing := request.Clone(request.Context())
+	if body != nil {
+		outgoing.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	response, err := r.transport.RoundTrip(outgoing)
+	if err != nil {
+		return nil, err
+	}
+
+	responseBody, err := readBody(response.Body)
+	if err != nil {
+		return nil, err
+	}
+	response.Body = io.NopCloser(bytes.NewReader(responseBody))
+
+	// Bodies are normalized, so the recorded length may not match them.
+	header := scrubHeader(response.Header)
+	header.Del("Content-Length")
+
+	r.mu.Lock()
+	r.cassette.Interactions = append(r.cassette.Interactions, &Interaction{
+		Request: recorded,
+		Response: Response{
+			StatusCode: response.StatusCode,
+			Header:     header,
+			Body:       newBody(responseBody),
+		},
+	})
+	r.mu.Unlock()
+
+	return response, nil
+}
+
+// replay returns the response of the first unused interaction matching the
+// request, so repeated requests get their responses in the recorded order.
+func (r *Recorder) replay(request *http.Request, recorded *Request) (*http.Response, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, interaction := range r.cassette.Interactions {
+		if interaction.used || !interaction.Request.matches(recorded) {
+			continue
+		}
+		interaction.used = true
+
+		body := interaction.Response.Body.Bytes()
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", interaction.Response.StatusCode, http.StatusText(interaction.Response.StatusCode)),
+			StatusCode:    interaction.Response.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        interaction.Response.Header.Clone(),
+			Body:          io.NopCloser(bytes.NewReader(body)),
+			ContentLength: int64(len(body)),
+			Request:       request,
+		}, nil
+	}
+
+	return nil, fmt.Errorf("%w: %s %s", ErrInteractionNotFound, recorded.Method, recorded.Path)
+}
+
+// Stop writes the cassette when recording. Replaying returns an error when
+// some recorded interactions were not replayed.
+func (r *Recorder) Stop() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.mode == ModeRecord {
+		return r.cassette.save(r.path)
+	}
+
+	unused := 0
+	for _, interaction := range r.cassette.Interactions {
+		if !interaction.used {
+			unused++
+		}
+	}
+	if unused > 0 {
+		return fmt.Errorf("sp-go: recorder: %d interactions of %s were not replayed", unused, r.path)
+	}
+
+	return nil
+}
+
+// readBody reads and closes the body.
+func readBody(body io.ReadCloser) ([]byte, error) {
+	if body == nil || body == http.NoBody {
+		return nil, nil
+	}
+	defer body.Close()
+
+	return io.ReadAll(body)
+}
@@ -0,0 +1,106 @@
+package recorder_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	v1 "terraform-provider-servicepipe/internal/pkg/sdkv1"
+	"terraform-provider-servicepipe/internal/pkg/sdkv1/l7origin"
+	"terraform-provider-servicepipe/internal/pkg/sdkv1/recorder"
+)
+
+// replayEndpoint is the endpoint of replayed clients, the host is never
+// dialed.
+const replayEndpoint = "https://api.servicepipe.invalid/api/v1"
+
+func TestReplayListAll(t *testing.T) {
+	rec, err := recorder.New("testdata/l7origin_list_all.json", recorder.ModeReplay, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := v1.NewClientV1WithCustomHTTP(rec.HTTPClient(), "token", replayEndpoint)
+
+	origins, _, err := l7origin.ListAll(context.Background(), client, &l7origin.ListOpts{L7ResourceID: 10, Limit: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantIPs := []string{"198.51.100.1", "198.51.100.2", "198.51.100.3"}
+	if len(origins) != len(wantIPs) {
+		t.Fatalf("got %d origins, want %d", len(origins), len(wantIPs))
+	}
+	for i, origin := range origins {
+		if origin.IP != wantIPs[i] {
+			t.Errorf("got origin %s at %d, want %s", origin.IP, i, wantIPs[i])
+		}
+	}
+
+	if err := rec.Stop(); err != nil {
+		t.Errorf("got stop error: %v", err)
+	}
+}
+
+func TestReplayUnrecordedRequest(t *testing.T) {
+	rec, err := recorder.New("testdata/l7origin_list_all.json", recorder.ModeReplay, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := v1.NewClientV1WithCustomHTTP(rec.HTTPClient(), "token", replayEndpoint)
+
+	_, _, err = l7origin.ListAll(context.Background(), client, &l7origin.ListOpts{L7ResourceID: 11, Limit: 2})
+	if !errors.Is(err, recorder.ErrInteractionNotFound) {
+		t.Errorf("got error %v, want %v", err, recorder.ErrInteractionNotFound)
+	}
+
+	if err := rec.Stop(); err == nil {
+		t.Errorf("got no stop error with interactions that were not replayed")
+	}
+}
+
+func TestRecordScrubs(t *testing.T) {
+	const secret = "s3cr3t-token-value"
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Set-Cookie", "session="+secret)
+		_, _ = w.Write([]byte(`{"data":{"result":{"token":"` + secret + `","customSslKey":"` + secret + `","customSslCrt":""}}}`))
+	}))
+	t.Cleanup(server.Close)
+
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	rec, err := recorder.New(path, recorder.ModeRecord, server.Client().Transport)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := v1.NewClientV1WithCustomHTTP(rec.HTTPClient(), secret, server.URL)
+
+	if _, _, err := client.Echo(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := rec.Stop(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(data, []byte(secret)) {
+		t.Errorf("the cassette contains the secret:\n%s", data)
+	}
+	for _, header := range []string{"Authorization", "Set-Cookie"} {
+		if strings.Contains(string(data), header) {
+			t.Errorf("the cassette contains the %s header:\n%s", header, data)
+		}
+	}
+	if !strings.Contains(string(data), `"customSslCrt": ""`) {
+		t.Errorf("the empty customSslCrt was not kept:\n%s", data)
+	}
+}
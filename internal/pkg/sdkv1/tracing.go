@@ -0,0 +1,85 @@
+package sdkv1
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName is the instrumentation name of spans started by the client.
+const tracerName = "terraform-provider-servicepipe/internal/pkg/sdkv1"
+
+// urlTemplateKey is the attribute of the route template of a request.
+const urlTemplateKey = attribute.Key("url.template")
+
+// startRequestSpan starts the span of an API request with the global tracer
+// provider, which is a no-op unless tracing is set up. Trace context is
+// propagated with the request headers. The client sends every request once, so
+// the resend count of the span is always zero.
+func (client *Client) startRequestSpan(ctx context.Context, request *http.Request) (context.Context, trace.Span) {
+	route := routeTemplate(client.Endpoint, request.URL)
+	ctx, span := otel.Tracer(tracerName).Start(ctx, request.Method+" "+route,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.HTTPRequestMethodKey.String(request.Method),
+			urlTemplateKey.String(route),
+			semconv.ServerAddress(request.URL.Hostname()),
+			semconv.HTTPRequestResendCount(0),
+		),
+	)
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(request.Header))
+
+	return ctx, span
+}
+
+// endRequestSpan records the outcome of an API request and ends its span. A nil
+// response with an error is a request that was not sent, such as one rejected
+// by the circuit breaker.
+func endRequestSpan(span trace.Span, response *http.Response, err error) {
+	if response != nil {
+		span.SetAttributes(semconv.HTTPResponseStatusCode(response.StatusCode))
+		if response.StatusCode >= http.StatusBadRequest {
+			span.SetStatus(codes.Error, http.StatusText(response.StatusCode))
+		}
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// routeTemplate returns the path of the request relative to the endpoint with
+// identifiers replaced by placeholders, such as l7/origin/{resource}/{id}, so
+// spans of the same API call are grouped.
+func routeTemplate(endpoint string, u *url.URL) string {
+	path := u.Path
+	if base, err := url.Parse(endpoint); err == nil {
+		path = strings.TrimPrefix(path, strings.TrimSuffix(base.Path, "/"))
+	}
+
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	placeholders := []string{"{id}", "{resource}"}
+	for i := len(segments) - 1; i >= 0; i-- {
+		if _, err := strconv.ParseInt(segments[i], 10, 64); err != nil {
+			continue
+		}
+
+		placeholder := "{param}"
+		if len(placeholders) > 0 {
+			placeholder, placeholders = placeholders[0], placeholders[1:]
+		}
+		segments[i] = placeholder
+	}
+
+	return strings.Join(segments, "/")
+}
@@ -0,0 +1,90 @@
+package sdkv1_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	v1 "terraform-provider-servicepipe/internal/pkg/sdkv1"
+)
+
+// recordSpans records spans of the global tracer provider during the test.
+func recordSpans(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	return recorder
+}
+
+// spanAttribute returns the value of the span attribute.
+func spanAttribute(span sdktrace.ReadOnlySpan, key attribute.Key) (attribute.Value, bool) {
+	for _, kv := range span.Attributes() {
+		if kv.Key == key {
+			return kv.Value, true
+		}
+	}
+
+	return attribute.Value{}, false
+}
+
+func TestRequestSpans(t *testing.T) {
+	recorder := recordSpans(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	t.Cleanup(server.Close)
+
+	client := v1.NewClientV1WithCustomHTTP(server.Client(), "token", server.URL)
+	client.CircuitBreaker = v1.NewCircuitBreaker(1, time.Hour)
+
+	ctx := context.Background()
+	if _, err := client.DoRequest(ctx, http.MethodGet, server.URL+"/l7/origin/1/2", nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.DoRequest(ctx, http.MethodGet, server.URL+"/l7/origin/1/2", nil); !errors.Is(err, v1.ErrCircuitOpen) {
+		t.Fatalf("got error %v, want %v", err, v1.ErrCircuitOpen)
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("got %d spans, want 2", len(spans))
+	}
+
+	for i, span := range spans {
+		if span.Name() != "GET l7/origin/{resource}/{id}" {
+			t.Errorf("got span %d name %q", i, span.Name())
+		}
+		if span.Status().Code != codes.Error {
+			t.Errorf("got span %d status %v, want an error", i, span.Status())
+		}
+		if value, ok := spanAttribute(span, "http.request.resend_count"); !ok || value.AsInt64() != 0 {
+			t.Errorf("got span %d resend count %v, want 0", i, value.Emit())
+		}
+	}
+
+	if value, ok := spanAttribute(spans[0], "http.response.status_code"); !ok || value.AsInt64() != http.StatusBadGateway {
+		t.Errorf("got status code %v of the sent request, want %d", value.Emit(), http.StatusBadGateway)
+	}
+
+	rejected := spans[1]
+	if _, ok := spanAttribute(rejected, "http.response.status_code"); ok {
+		t.Errorf("the rejected request has a status code")
+	}
+	if events := rejected.Events(); len(events) == 0 || events[0].Name != "exception" {
+		t.Errorf("got events %v of the rejected request, want the recorded error", events)
+	}
+}
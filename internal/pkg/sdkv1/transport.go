@@ -0,0 +1,125 @@
+package sdkv1
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// TransportOpts represents TLS and proxy options of the HTTP transport of a
+// client.
+type TransportOpts struct {
+	// CACertPEM contains PEM encoded certificates trusted in addition to the
+	// system trust store, such as the one of a TLS intercepting proxy.
+	CACertPEM []byte
+
+	// ClientCertPEM and ClientKeyPEM contain the PEM encoded certificate and
+	// key the client authenticates with, both must be set.
+	ClientCertPEM []byte
+	ClientKeyPEM  []byte
+
+	// ProxyURL is the URL of the proxy all requests are sent through, the
+	// proxy of the environment is used when it is empty.
+	ProxyURL string
+
+	// InsecureSkipVerify disables verification of the server certificate.
+	InsecureSkipVerify bool
+}
+
+// NewClientV1WithTransportOpts initializes a new client for the API V1 whose
+// HTTP transport uses the TLS and proxy options.
+func NewClientV1WithTransportOpts(token, endpoint string, opts *TransportOpts) (*Client, error) {
+	transport, err := newHTTPTransportWithOpts(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	httpClient := newHTTPClient()
+	httpClient.Transport = transport
+
+	return NewClientV1WithCustomHTTP(httpClient, token, endpoint), nil
+}
+
+// newHTTPTransportWithOpts returns the default HTTP transport with the TLS and
+// proxy options applied.
+func newHTTPTransportWithOpts(opts *TransportOpts) (*http.Transport, error) {
+	transport := newHTTPTransport()
+	if opts == nil {
+		return transport, nil
+	}
+
+	if opts.ProxyURL != "" {
+		proxyURL, err := parseProxyURL(opts.ProxyURL)
+		if err != nil {
+			return nil, err
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	tlsConfig, err := opts.tlsConfig()
+	if err != nil {
+		return nil, err
+	}
+	transport.TLSClientConfig = tlsConfig
+
+	return transport, nil
+}
+
+// tlsConfig returns the TLS configuration of the options, nil when the
+// defaults are used.
+func (opts *TransportOpts) tlsConfig() (*tls.Config, error) {
+	if len(opts.CACertPEM) == 0 && len(opts.ClientCertPEM) == 0 && len(opts.ClientKeyPEM) == 0 && !opts.InsecureSkipVerify {
+		return nil, nil
+	}
+
+	config := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: opts.InsecureSkipVerify,
+	}
+
+	if len(opts.CACertPEM) > 0 {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(opts.CACertPEM) {
+			return nil, fmt.Errorf("sp-go: no valid certificates found in the CA certificate PEM")
+		}
+		config.RootCAs = pool
+	}
+
+	if len(opts.ClientCertPEM) > 0 || len(opts.ClientKeyPEM) > 0 {
+		if len(opts.ClientCertPEM) == 0 || len(opts.ClientKeyPEM) == 0 {
+			return nil, fmt.Errorf("sp-go: both the client certificate and the client key must be set")
+		}
+
+		certificate, err := tls.X509KeyPair(opts.ClientCertPEM, opts.ClientKeyPEM)
+		if err != nil {
+			return nil, fmt.Errorf("sp-go: could not load the client certificate: %w", err)
+		}
+		config.Certificates = []tls.Certificate{certificate}
+	}
+
+	return config, nil
+}
+
+// parseProxyURL parses the URL of a HTTP, HTTPS or SOCKS5 proxy.
+func parseProxyURL(rawURL string) (*url.URL, error) {
+	proxyURL, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("sp-go: invalid proxy URL: %w", err)
+	}
+
+	switch proxyURL.Scheme {
+	case "http", "https", "socks5":
+	default:
+		return nil, fmt.Errorf("sp-go: unsupported proxy URL scheme %q, expected http, https or socks5", proxyURL.Scheme)
+	}
+	if proxyURL.Host == "" {
+		return nil, fmt.Errorf("sp-go: proxy URL %q has no host", rawURL)
+	}
+
+	return proxyURL, nil
+}
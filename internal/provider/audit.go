@@ -0,0 +1,85 @@
+package provider
+
+import (
+	"context"
+	"strconv"
+	"sync"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+
+	v1 "terraform-provider-servicepipe/internal/pkg/sdkv1"
+)
+
+// withAuditAddress returns a context whose mutating API calls are recorded in
+// the audit log with the resource. Terraform does not pass configuration
+// addresses to providers, so the address is the resource type keyed by the
+// identity of the resource, such as servicepipe_l7resource["example.com"].
+func withAuditAddress(ctx context.Context, typeName, key string) context.Context {
+	return v1.WithAuditAddress(ctx, typeName+"["+strconv.Quote(key)+"]")
+}
+
+// newAuditor opens the audit log, events that could not be written are logged
+// as errors.
+func newAuditor(path string) (*v1.FileAuditor, error) {
+	auditor, err := v1.NewFileAuditor(path)
+	if err != nil {
+		return nil, err
+	}
+
+	auditor.OnError = func(ctx context.Context, err error) {
+		tflog.Error(ctx, "could not write the audit log", map[string]any{"path": path, "error": err.Error()})
+	}
+
+	return auditor, nil
+}
+
+// auditLog keeps the audit log of the provider open across Configure calls,
+// so the file is opened once per path and closed when it is no longer used.
+type auditLog struct {
+	mu      sync.Mutex
+	path    string
+	auditor *v1.FileAuditor
+}
+
+// open returns the auditor of the path, the one opened before is reused when
+// the path did not change and closed otherwise.
+func (l *auditLog) open(path string) (*v1.FileAuditor, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.auditor != nil && l.path == path {
+		return l.auditor, nil
+	}
+	if err := l.closeLocked(); err != nil {
+		return nil, err
+	}
+
+	auditor, err := newAuditor(path)
+	if err != nil {
+		return nil, err
+	}
+	l.path = path
+	l.auditor = auditor
+
+	return auditor, nil
+}
+
+// close closes the auditor opened before, if any.
+func (l *auditLog) close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.closeLocked()
+}
+
+func (l *auditLog) closeLocked() error {
+	if l.auditor == nil {
+		return nil
+	}
+
+	err := l.auditor.Close()
+	l.path = ""
+	l.auditor = nil
+
+	return err
+}
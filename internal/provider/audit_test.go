@@ -0,0 +1,46 @@
+package provider
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestAuditLogOpen(t *testing.T) {
+	dir := t.TempDir()
+	first := filepath.Join(dir, "first.jsonl")
+	second := filepath.Join(dir, "second.jsonl")
+
+	var l auditLog
+	t.Cleanup(func() { _ = l.close() })
+
+	auditor, err := l.open(first)
+	if err != nil {
+		t.Fatalf("could not open the audit log: %v", err)
+	}
+
+	reused, err := l.open(first)
+	if err != nil {
+		t.Fatalf("could not open the audit log again: %v", err)
+	}
+	if reused != auditor {
+		t.Errorf("the auditor of an unchanged path was not reused")
+	}
+
+	changed, err := l.open(second)
+	if err != nil {
+		t.Fatalf("could not open the changed audit log: %v", err)
+	}
+	if changed == auditor {
+		t.Errorf("the auditor of a changed path was reused")
+	}
+	if err := auditor.Close(); err == nil {
+		t.Errorf("the auditor of the previous path was not closed")
+	}
+
+	if err := l.close(); err != nil {
+		t.Fatalf("could not close the audit log: %v", err)
+	}
+	if l.auditor != nil {
+		t.Errorf("the auditor was kept after close")
+	}
+}
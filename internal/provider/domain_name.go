@@ -0,0 +1,88 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	l7resource "terraform-provider-servicepipe/internal/pkg/sdkv1/l7resource"
+)
+
+// expandDomainName returns the ASCII form of a domain name the API stores, names
+// that can't be converted are sent as is for the API to reject them.
+func expandDomainName(value types.String) string {
+	name, err := l7resource.NormalizeName(value.ValueString())
+	if err != nil {
+		return value.ValueString()
+	}
+
+	return name
+}
+
+// flattenDomainName returns the ASCII and Unicode forms of a domain name.
+func flattenDomainName(value types.String) (ascii, unicode types.String) {
+	if value.IsNull() || value.IsUnknown() {
+		return types.StringUnknown(), types.StringUnknown()
+	}
+
+	asciiName, err := l7resource.NormalizeName(value.ValueString())
+	if err != nil {
+		return types.StringValue(value.ValueString()), types.StringValue(value.ValueString())
+	}
+
+	unicodeName, err := l7resource.UnicodeName(asciiName)
+	if err != nil {
+		unicodeName = asciiName
+	}
+
+	return types.StringValue(asciiName), types.StringValue(unicodeName)
+}
+
+// domainNamesEqual reports whether known domain names are semantically equal.
+func domainNamesEqual(a, b types.String) bool {
+	if a.IsNull() || a.IsUnknown() || b.IsNull() || b.IsUnknown() {
+		return a.Equal(b)
+	}
+
+	return l7resource.NamesEqual(a.ValueString(), b.ValueString())
+}
+
+var _ validator.String = domainNameValidator{}
+
+// domainNameValidator validates that a string attribute is a domain name which
+// can be converted into its ASCII form.
+type domainNameValidator struct{}
+
+// Description describes the validation in plain text formatting.
+func (v domainNameValidator) Description(_ context.Context) string {
+	return "value must be a valid domain name"
+}
+
+// MarkdownDescription describes the validation in Markdown formatting.
+func (v domainNameValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+// ValidateString performs the validation.
+func (v domainNameValidator) ValidateString(ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	value := req.ConfigValue.ValueString()
+	if _, err := l7resource.NormalizeName(value); err != nil {
+		resp.Diagnostics.AddAttributeError(
+			req.Path,
+			"Invalid Domain Name",
+			fmt.Sprintf("Attribute %s %s, got: %q: %s", req.Path, v.Description(ctx), value, err),
+		)
+	}
+}
+
+// isDomainName returns a validator which ensures that any configured attribute
+// value is a valid, possibly internationalized, domain name.
+func isDomainName() validator.String {
+	return domainNameValidator{}
+}
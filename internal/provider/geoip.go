@@ -0,0 +1,168 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	l7resource "terraform-provider-servicepipe/internal/pkg/sdkv1/l7resource"
+)
+
+const (
+	// geoipModeOff disables GeoIP filtering.
+	geoipModeOff = "off"
+
+	// geoipModeAllow allows access only from countries of geoip_list.
+	geoipModeAllow = "allow"
+
+	// geoipModeDeny denies access from countries of geoip_list.
+	geoipModeDeny = "deny"
+)
+
+// geoipModes maps geoip_mode values to the API GeoIP modes.
+var geoipModes = map[string]int{
+	geoipModeOff:   l7resource.GeoipModeOff,
+	geoipModeAllow: l7resource.GeoipModeAllow,
+	geoipModeDeny:  l7resource.GeoipModeDeny,
+}
+
+// geoipCountries contains ISO 3166-1 alpha-2 country codes.
+var geoipCountries = map[string]struct{}{
+	"AD": {}, "AE": {}, "AF": {}, "AG": {}, "AI": {}, "AL": {}, "AM": {}, "AO": {}, "AQ": {}, "AR": {}, "AS": {}, "AT": {},
+	"AU": {}, "AW": {}, "AX": {}, "AZ": {}, "BA": {}, "BB": {}, "BD": {}, "BE": {}, "BF": {}, "BG": {}, "BH": {}, "BI": {},
+	"BJ": {}, "BL": {}, "BM": {}, "BN": {}, "BO": {}, "BQ": {}, "BR": {}, "BS": {}, "BT": {}, "BV": {}, "BW": {}, "BY": {},
+	"BZ": {}, "CA": {}, "CC": {}, "CD": {}, "CF": {}, "CG": {}, "CH": {}, "CI": {}, "CK": {}, "CL": {}, "CM": {}, "CN": {},
+	"CO": {}, "CR": {}, "CU": {}, "CV": {}, "CW": {}, "CX": {}, "CY": {}, "CZ": {}, "DE": {}, "DJ": {}, "DK": {}, "DM": {},
+	"DO": {}, "DZ": {}, "EC": {}, "EE": {}, "EG": {}, "EH": {}, "ER": {}, "ES": {}, "ET": {}, "FI": {}, "FJ": {}, "FK": {},
+	"FM": {}, "FO": {}, "FR": {}, "GA": {}, "GB": {}, "GD": {}, "GE": {}, "GF": {}, "GG": {}, "GH": {}, "GI": {}, "GL": {},
+	"GM": {}, "GN": {}, "GP": {}, "GQ": {}, "GR": {}, "GS": {}, "GT": {}, "GU": {}, "GW": {}, "GY": {}, "HK": {}, "HM": {},
+	"HN": {}, "HR": {}, "HT": {}, "HU": {}, "ID": {}, "IE": {}, "IL": {}, "IM": {}, "IN": {}, "IO": {}, "IQ": {}, "IR": {},
+	"IS": {}, "IT": {}, "JE": {}, "JM": {}, "JO": {}, "JP": {}, "KE": {}, "KG": {}, "KH": {}, "KI": {}, "KM": {}, "KN": {},
+	"KP": {}, "KR": {}, "KW": {}, "KY": {}, "KZ": {}, "LA": {}, "LB": {}, "LC": {}, "LI": {}, "LK": {}, "LR": {}, "LS": {},
+	"LT": {}, "LU": {}, "LV": {}, "LY": {}, "MA": {}, "MC": {}, "MD": {}, "ME": {}, "MF": {}, "MG": {}, "MH": {}, "MK": {},
+	"ML": {}, "MM": {}, "MN": {}, "MO": {}, "MP": {}, "MQ": {}, "MR": {}, "MS": {}, "MT": {}, "MU": {}, "MV": {}, "MW": {},
+	"MX": {}, "MY": {}, "MZ": {}, "NA": {}, "NC": {}, "NE": {}, "NF": {}, "NG": {}, "NI": {}, "NL": {}, "NO": {}, "NP": {},
+	"NR": {}, "NU": {}, "NZ": {}, "OM": {}, "PA": {}, "PE": {}, "PF": {}, "PG": {}, "PH": {}, "PK": {}, "PL": {}, "PM": {},
+	"PN": {}, "PR": {}, "PS": {}, "PT": {}, "PW": {}, "PY": {}, "QA": {}, "RE": {}, "RO": {}, "RS": {}, "RU": {}, "RW": {},
+	"SA": {}, "SB": {}, "SC": {}, "SD": {}, "SE": {}, "SG": {}, "SH": {}, "SI": {}, "SJ": {}, "SK": {}, "SL": {}, "SM": {},
+	"SN": {}, "SO": {}, "SR": {}, "SS": {}, "ST": {}, "SV": {}, "SX": {}, "SY": {}, "SZ": {}, "TC": {}, "TD": {}, "TF": {},
+	"TG": {}, "TH": {}, "TJ": {}, "TK": {}, "TL": {}, "TM": {}, "TN": {}, "TO": {}, "TR": {}, "TT": {}, "TV": {}, "TW": {},
+	"TZ": {}, "UA": {}, "UG": {}, "UM": {}, "US": {}, "UY": {}, "UZ": {}, "VA": {}, "VC": {}, "VE": {}, "VG": {}, "VI": {},
+	"VN": {}, "VU": {}, "WF": {}, "WS": {}, "YE": {}, "YT": {}, "ZA": {}, "ZM": {}, "ZW": {},
+}
+
+// expandGeoipMode converts a geoip_mode value into the API GeoIP mode.
+func expandGeoipMode(value types.String) int {
+	if mode, ok := geoipModes[value.ValueString()]; ok {
+		return mode
+	}
+
+	return l7resource.GeoipModeOff
+}
+
+// flattenGeoipMode converts the API GeoIP mode into a geoip_mode value. Modes
+// unknown to the provider are kept as numbers, so they show up in a diff.
+func flattenGeoipMode(mode int) types.String {
+	for name, value := range geoipModes {
+		if value == mode {
+			return types.StringValue(name)
+		}
+	}
+
+	return types.StringValue(strconv.Itoa(mode))
+}
+
+// expandGeoipList serializes country codes into the API comma separated list.
+func expandGeoipList(value types.Set) string {
+	return strings.Join(geoipListCodes(value), ",")
+}
+
+// flattenGeoipList parses the API country list, so ordering, case and
+// whitespace never cause a diff.
+func flattenGeoipList(list string) types.Set {
+	seen := make(map[string]bool)
+	elements := []attr.Value{}
+	for _, code := range strings.FieldsFunc(list, func(r rune) bool {
+		return r == ',' || r == ';' || unicode.IsSpace(r)
+	}) {
+		code = strings.ToUpper(code)
+		if seen[code] {
+			continue
+		}
+		seen[code] = true
+		elements = append(elements, types.StringValue(code))
+	}
+
+	return types.SetValueMust(types.StringType, elements)
+}
+
+// geoipListCodes returns sorted unique country codes of a geoip_list value.
+func geoipListCodes(value types.Set) []string {
+	if value.IsNull() || value.IsUnknown() {
+		return nil
+	}
+
+	seen := make(map[string]bool)
+	codes := make([]string, 0, len(value.Elements()))
+	for _, element := range value.Elements() {
+		code, ok := element.(types.String)
+		if !ok || code.IsNull() || code.IsUnknown() {
+			continue
+		}
+
+		normalized := strings.ToUpper(strings.TrimSpace(code.ValueString()))
+		if normalized == "" || seen[normalized] {
+			continue
+		}
+		seen[normalized] = true
+		codes = append(codes, normalized)
+	}
+	sort.Strings(codes)
+
+	return codes
+}
+
+var _ validator.String = countryCodeValidator{}
+
+// countryCodeValidator validates that a string attribute is an upper case
+// ISO 3166-1 alpha-2 country code.
+type countryCodeValidator struct{}
+
+// Description describes the validation in plain text formatting.
+func (v countryCodeValidator) Description(_ context.Context) string {
+	return "value must be an upper case ISO 3166-1 alpha-2 country code"
+}
+
+// MarkdownDescription describes the validation in Markdown formatting.
+func (v countryCodeValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+// ValidateString performs the validation.
+func (v countryCodeValidator) ValidateString(ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	value := req.ConfigValue.ValueString()
+	if _, ok := geoipCountries[value]; !ok {
+		resp.Diagnostics.AddAttributeError(
+			req.Path,
+			"Invalid Country Code",
+			fmt.Sprintf("Attribute %s %s, got: %q", req.Path, v.Description(ctx), value),
+		)
+	}
+}
+
+// isCountryCode returns a validator which ensures that any configured attribute
+// value is a known ISO 3166-1 alpha-2 country code.
+func isCountryCode() validator.String {
+	return countryCodeValidator{}
+}
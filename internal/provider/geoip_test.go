@@ -0,0 +1,147 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	l7resource "terraform-provider-servicepipe/internal/pkg/sdkv1/l7resource"
+)
+
+// newGeoipList returns a geoip_list value of the codes.
+func newGeoipList(codes ...string) types.Set {
+	elements := make([]attr.Value, 0, len(codes))
+	for _, code := range codes {
+		elements = append(elements, types.StringValue(code))
+	}
+
+	return types.SetValueMust(types.StringType, elements)
+}
+
+func TestExpandGeoipList(t *testing.T) {
+	tests := map[string]struct {
+		value types.Set
+		want  string
+	}{
+		"null":               {value: types.SetNull(types.StringType), want: ""},
+		"unknown":            {value: types.SetUnknown(types.StringType), want: ""},
+		"empty":              {value: newGeoipList(), want: ""},
+		"single":             {value: newGeoipList("RU"), want: "RU"},
+		"shuffled":           {value: newGeoipList("RU", "DE", "BY"), want: "BY,DE,RU"},
+		"mixed case":         {value: newGeoipList("ru", "By"), want: "BY,RU"},
+		"padded":             {value: newGeoipList(" RU ", "BY\t"), want: "BY,RU"},
+		"duplicates by case": {value: newGeoipList("ru", "RU", " Ru"), want: "RU"},
+		"blank":              {value: newGeoipList("  ", "RU"), want: "RU"},
+		"null element": {
+			value: types.SetValueMust(types.StringType, []attr.Value{types.StringNull(), types.StringValue("RU")}),
+			want:  "RU",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := expandGeoipList(test.value); got != test.want {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestFlattenGeoipList(t *testing.T) {
+	tests := map[string]struct {
+		list string
+		want types.Set
+	}{
+		"empty":            {list: "", want: newGeoipList()},
+		"separators only":  {list: " ,;\n", want: newGeoipList()},
+		"comma separated":  {list: "RU,BY", want: newGeoipList("BY", "RU")},
+		"shuffled":         {list: "DE,RU,BY", want: newGeoipList("BY", "DE", "RU")},
+		"mixed case":       {list: "ru,By", want: newGeoipList("BY", "RU")},
+		"padded":           {list: " RU , BY ", want: newGeoipList("BY", "RU")},
+		"other separators": {list: "RU;BY\tDE\r\nKZ", want: newGeoipList("BY", "DE", "KZ", "RU")},
+		"duplicates":       {list: "RU,ru, Ru", want: newGeoipList("RU")},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := flattenGeoipList(test.list)
+			if !got.Equal(test.want) {
+				t.Errorf("got %s, want %s", got, test.want)
+			}
+
+			// The expanded list is stable, so writing back what was read
+			// never causes a diff.
+			if expanded := expandGeoipList(got); !flattenGeoipList(expanded).Equal(got) {
+				t.Errorf("got %s after a round trip of %q, want %s", flattenGeoipList(expanded), expanded, got)
+			}
+		})
+	}
+}
+
+func TestGeoipListWithoutDiff(t *testing.T) {
+	// Lists of the same countries differing only in order, case and
+	// whitespace are equal once read and serialized the same.
+	lists := []string{"BY,DE,RU", "RU,BY,DE", "ru,by,de", " De ;Ru\tBY ", "RU,BY,DE,ru"}
+	want := flattenGeoipList(lists[0])
+	for _, list := range lists {
+		if got := flattenGeoipList(list); !got.Equal(want) {
+			t.Errorf("got %s of %q, want %s", got, list, want)
+		}
+	}
+
+	configs := []types.Set{
+		newGeoipList("BY", "DE", "RU"),
+		newGeoipList("RU", "DE", "BY"),
+		newGeoipList("ru", " de", "By "),
+	}
+	wantList := expandGeoipList(configs[0])
+	for _, config := range configs {
+		if got := expandGeoipList(config); got != wantList {
+			t.Errorf("got %q of %s, want %q", got, config, wantList)
+		}
+	}
+}
+
+func TestFlattenGeoipMode(t *testing.T) {
+	tests := map[string]struct {
+		mode int
+		want types.String
+	}{
+		"off":          {mode: l7resource.GeoipModeOff, want: types.StringValue(geoipModeOff)},
+		"allow":        {mode: l7resource.GeoipModeAllow, want: types.StringValue(geoipModeAllow)},
+		"deny":         {mode: l7resource.GeoipModeDeny, want: types.StringValue(geoipModeDeny)},
+		"unknown":      {mode: 3, want: types.StringValue("3")},
+		"negative":     {mode: -1, want: types.StringValue("-1")},
+		"large number": {mode: 1000, want: types.StringValue("1000")},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := flattenGeoipMode(test.mode); !got.Equal(test.want) {
+				t.Errorf("got %s, want %s", got, test.want)
+			}
+		})
+	}
+}
+
+func TestExpandGeoipMode(t *testing.T) {
+	tests := map[string]struct {
+		value types.String
+		want  int
+	}{
+		"off":     {value: types.StringValue(geoipModeOff), want: l7resource.GeoipModeOff},
+		"allow":   {value: types.StringValue(geoipModeAllow), want: l7resource.GeoipModeAllow},
+		"deny":    {value: types.StringValue(geoipModeDeny), want: l7resource.GeoipModeDeny},
+		"unknown": {value: types.StringValue("3"), want: l7resource.GeoipModeOff},
+		"null":    {value: types.StringNull(), want: l7resource.GeoipModeOff},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := expandGeoipMode(test.value); got != test.want {
+				t.Errorf("got %d, want %d", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package provider
+
+import (
+	"context"
+
+	v1 "terraform-provider-servicepipe/internal/pkg/sdkv1"
+	l7origin "terraform-provider-servicepipe/internal/pkg/sdkv1/l7origin"
+)
+
+// l7originCache keeps origins of l7 resources for the duration of a single
+// operation, so every l7 resource is listed once however many of its origins
+// are looked up.
+type l7originCache struct {
+	client  *v1.Client
+	origins map[int64][]*l7origin.Item
+}
+
+// newL7originCache returns an empty origin cache.
+func newL7originCache(client *v1.Client) *l7originCache {
+	return &l7originCache{
+		client:  client,
+		origins: make(map[int64][]*l7origin.Item),
+	}
+}
+
+// list returns all origins of the l7 resource.
+func (c *l7originCache) list(ctx context.Context, l7ResourceID int64) ([]*l7origin.Item, error) {
+	if origins, ok := c.origins[l7ResourceID]; ok {
+		return origins, nil
+	}
+
+	origins, err := listL7Origins(ctx, c.client, l7ResourceID)
+	if err != nil {
+		return nil, err
+	}
+	c.origins[l7ResourceID] = origins
+
+	return origins, nil
+}
+
+// byIP returns origins of the l7 resource by their IPs.
+func (c *l7originCache) byIP(ctx context.Context, l7ResourceID int64) (map[string]*l7origin.Item, error) {
+	origins, err := c.list(ctx, l7ResourceID)
+	if err != nil {
+		return nil, err
+	}
+
+	byIP := make(map[string]*l7origin.Item, len(origins))
+	for _, item := range origins {
+		byIP[item.IP] = item
+	}
+
+	return byIP, nil
+}
+
+// byID returns origins of the l7 resource by their IDs.
+func (c *l7originCache) byID(ctx context.Context, l7ResourceID int64) (map[int64]*l7origin.Item, error) {
+	origins, err := c.list(ctx, l7ResourceID)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[int64]*l7origin.Item, len(origins))
+	for _, item := range origins {
+		byID[item.ID] = item
+	}
+
+	return byID, nil
+}
+
+// forget drops cached origins of the l7 resource after they were changed.
+func (c *l7originCache) forget(l7ResourceID int64) {
+	delete(c.origins, l7ResourceID)
+}
@@ -0,0 +1,444 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64default"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	v1 "terraform-provider-servicepipe/internal/pkg/sdkv1"
+	l7origin "terraform-provider-servicepipe/internal/pkg/sdkv1/l7origin"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ resource.Resource               = &l7originResource{}
+	_ resource.ResourceWithConfigure  = &l7originResource{}
+	_ resource.ResourceWithModifyPlan = &l7originResource{}
+)
+
+// NewL7originResource is a helper function to simplify the provider implementation.
+func NewL7originResource() resource.Resource {
+	return &l7originResource{
+		resolver: newNetHostResolver(),
+	}
+}
+
+// l7originResource is the resource implementation.
+type l7originResource struct {
+	client *v1.Client
+
+	// maxParallelRequests limits origin requests made at the same time.
+	maxParallelRequests int
+
+	// resolver resolves origin hostnames.
+	resolver hostResolver
+}
+
+// l7originStandaloneResourceModel maps the resource schema data.
+type l7originStandaloneResourceModel struct {
+	L7ResourceID types.Int64  `tfsdk:"l7_resource_id"`
+	IP           types.String `tfsdk:"ip"`
+	Hostname     types.String `tfsdk:"hostname"`
+	Weight       types.Int64  `tfsdk:"weight"`
+	Mode         types.String `tfsdk:"mode"`
+	ResolvedIPs  types.List   `tfsdk:"resolved_ips"`
+	OriginIDs    types.List   `tfsdk:"origin_ids"`
+}
+
+// Metadata returns the resource type name.
+func (r *l7originResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_l7origin"
+}
+
+// Schema defines the schema for the resource.
+func (r *l7originResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Origin of a l7 resource managed separately from the `origins` of `servicepipe_l7resource`. " +
+			"An existing origin with the same IP is taken over by this resource.",
+		Attributes: map[string]schema.Attribute{
+			"l7_resource_id": schema.Int64Attribute{
+				Required: true,
+				PlanModifiers: []planmodifier.Int64{
+					int64planmodifier.RequiresReplace(),
+				},
+			},
+			"ip": schema.StringAttribute{
+				Optional: true,
+				Validators: []validator.String{
+					isIPAddress(),
+					stringvalidator.ExactlyOneOf(path.MatchRoot("hostname")),
+				},
+			},
+			"hostname": schema.StringAttribute{
+				Optional: true,
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+				},
+				MarkdownDescription: "Origin hostname resolved to A/AAAA records at plan time, " +
+					"one origin with the same weight and mode is created per resolved IP. Conflicts with `ip`.",
+			},
+			"weight": schema.Int64Attribute{
+				Optional: true,
+				Computed: true,
+				Default:  int64default.StaticInt64(50),
+				Validators: []validator.Int64{
+					int64validator.Between(l7origin.MinWeight, l7origin.MaxWeight),
+				},
+			},
+			"mode": schema.StringAttribute{
+				Optional: true,
+				Computed: true,
+				Default:  stringdefault.StaticString(l7origin.ModePrimary),
+				Validators: []validator.String{
+					stringvalidator.OneOf(l7origin.ModePrimary, l7origin.ModeBackup),
+				},
+			},
+			"resolved_ips": schema.ListAttribute{
+				Computed:            true,
+				ElementType:         types.StringType,
+				MarkdownDescription: "IP addresses the origin `hostname` was resolved to.",
+			},
+			"origin_ids": schema.ListAttribute{
+				Computed:            true,
+				ElementType:         types.Int64Type,
+				MarkdownDescription: "Identifiers of the created origins, one per origin IP.",
+			},
+		},
+	}
+}
+
+// ModifyPlan resolves the origin hostname, so the plan shows the resulting origin
+// IPs and DNS changes produce a diff.
+func (r *l7originResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// Nothing to resolve on destroy.
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var plan *l7originStandaloneResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	switch {
+	case plan.Hostname.IsUnknown():
+		plan.ResolvedIPs = types.ListUnknown(types.StringType)
+	case plan.Hostname.IsNull():
+		plan.ResolvedIPs = types.ListNull(types.StringType)
+	default:
+		ips, err := resolveHostname(ctx, r.resolver, plan.Hostname.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("hostname"),
+				"Error Resolving Origin Hostname",
+				err.Error(),
+			)
+			return
+		}
+		plan.ResolvedIPs = resolvedIPsValue(ips)
+	}
+
+	plan.OriginIDs = types.ListUnknown(types.Int64Type)
+	if !req.State.Raw.IsNull() {
+		var state *l7originStandaloneResourceModel
+		resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		// Origin IDs stay the same until the origin IPs change.
+		if plan.IP.Equal(state.IP) && plan.Hostname.Equal(state.Hostname) && plan.ResolvedIPs.Equal(state.ResolvedIPs) {
+			plan.OriginIDs = state.OriginIDs
+		}
+	}
+
+	resp.Diagnostics.Append(resp.Plan.Set(ctx, &plan)...)
+}
+
+// Configure adds the provider configured client to the resource.
+func (r *l7originResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	providerData, ok := req.ProviderData.(*servicepipeProviderData)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *servicepipeProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	r.client = providerData.client
+	r.maxParallelRequests = providerData.maxParallelRequests
+}
+
+// Create creates the resource and sets the initial Terraform state.
+func (r *l7originResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	ctx, span := startOperationSpan(ctx, "servicepipe_l7origin", "create")
+	defer func() { endOperationSpan(span, resp.Diagnostics) }()
+
+	var plan *l7originStandaloneResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx = withAuditAddress(ctx, "servicepipe_l7origin", plan.auditKey())
+
+	if err := plan.resolve(ctx, r.resolver); err != nil {
+		resp.Diagnostics.AddError(
+			"Error Resolving Origin Hostname",
+			err.Error(),
+		)
+		return
+	}
+
+	r.apply(ctx, plan, nil, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+// Read refreshes the Terraform state with the latest data.
+func (r *l7originResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	ctx, span := startOperationSpan(ctx, "servicepipe_l7origin", "read")
+	defer func() { endOperationSpan(span, resp.Diagnostics) }()
+
+	var state *l7originStandaloneResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	l7ResourceID := state.L7ResourceID.ValueInt64()
+	items, err := listL7Origins(ctx, r.client, l7ResourceID)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Reading Servicepipe l7 origins",
+			"Could not list l7 origins of l7 resource ID "+strconv.Itoa(int(l7ResourceID))+": "+err.Error(),
+		)
+		return
+	}
+
+	byIP := make(map[string]*l7origin.Item, len(items))
+	for _, item := range items {
+		byIP[item.IP] = item
+	}
+
+	var found []*l7origin.Item
+	for _, ip := range state.ips() {
+		if item, ok := byIP[ip]; ok {
+			found = append(found, item)
+		}
+	}
+
+	if len(found) == 0 {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	state.Weight = types.Int64Value(found[0].Weight)
+	state.Mode = types.StringValue(found[0].Mode)
+	state.setOrigins(found)
+
+	// Set refreshed state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+// Update updates the resource and sets the updated Terraform state on success.
+func (r *l7originResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	ctx, span := startOperationSpan(ctx, "servicepipe_l7origin", "update")
+	defer func() { endOperationSpan(span, resp.Diagnostics) }()
+
+	var plan, state *l7originStandaloneResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx = withAuditAddress(ctx, "servicepipe_l7origin", plan.auditKey())
+
+	if err := plan.resolve(ctx, r.resolver); err != nil {
+		resp.Diagnostics.AddError(
+			"Error Resolving Origin Hostname",
+			err.Error(),
+		)
+		return
+	}
+
+	r.apply(ctx, plan, state, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Set refreshed state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+// Delete deletes the resource and removes the Terraform state on success.
+func (r *l7originResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	ctx, span := startOperationSpan(ctx, "servicepipe_l7origin", "delete")
+	defer func() { endOperationSpan(span, resp.Diagnostics) }()
+
+	var state *l7originStandaloneResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx = withAuditAddress(ctx, "servicepipe_l7origin", state.auditKey())
+
+	l7ResourceID := state.L7ResourceID.ValueInt64()
+	current, err := r.currentOrigins(ctx, l7ResourceID, state.ips())
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Reading Servicepipe l7 origins",
+			"Could not list l7 origins of l7 resource ID "+strconv.Itoa(int(l7ResourceID))+": "+err.Error(),
+		)
+		return
+	}
+
+	if err := deleteOrigins(ctx, r.client, l7ResourceID, current, r.maxParallelRequests); err != nil {
+		appendErrorDiagnostics(&resp.Diagnostics, "Error Deleting l7origin", err)
+	}
+}
+
+// apply moves origins of the prior state to the plan and stores the resulting
+// origin IDs in the plan.
+func (r *l7originResource) apply(ctx context.Context, plan, state *l7originStandaloneResourceModel, diags *diag.Diagnostics) {
+	l7ResourceID := plan.L7ResourceID.ValueInt64()
+
+	ips := plan.ips()
+	if state != nil {
+		ips = append(ips, state.ips()...)
+	}
+
+	current, err := r.currentOrigins(ctx, l7ResourceID, ips)
+	if err != nil {
+		diags.AddError(
+			"Error Reading Servicepipe l7 origins",
+			"Could not list l7 origins of l7 resource ID "+strconv.Itoa(int(l7ResourceID))+": "+err.Error(),
+		)
+		return
+	}
+
+	desired := make([]*l7origin.Item, 0, len(plan.ips()))
+	for _, ip := range plan.ips() {
+		desired = append(desired, &l7origin.Item{
+			L7ResourceID: l7ResourceID,
+			IP:           ip,
+			Weight:       plan.Weight.ValueInt64(),
+			Mode:         plan.Mode.ValueString(),
+		})
+	}
+
+	originOpts := originUpdateOpts{Strategy: originUpdateStrategyDefault, Parallelism: r.maxParallelRequests}
+	originsByIP, err := applyOriginChanges(ctx, r.client, l7ResourceID, current, desired, originOpts)
+	if err != nil {
+		appendErrorDiagnostics(diags, "Error Updating Servicepipe l7 origins", err)
+		return
+	}
+
+	items := make([]*l7origin.Item, 0, len(desired))
+	for _, v := range desired {
+		items = append(items, originsByIP[v.IP])
+	}
+	plan.setOrigins(items)
+}
+
+// currentOrigins returns existing origins of the l7 resource with given IPs.
+func (r *l7originResource) currentOrigins(ctx context.Context, l7ResourceID int64, ips []string) ([]*l7origin.Item, error) {
+	items, err := listL7Origins(ctx, r.client, l7ResourceID)
+	if err != nil {
+		return nil, err
+	}
+
+	wanted := make(map[string]bool, len(ips))
+	for _, ip := range ips {
+		wanted[ip] = true
+	}
+
+	var current []*l7origin.Item
+	for _, item := range items {
+		if wanted[item.IP] {
+			current = append(current, item)
+		}
+	}
+
+	return current, nil
+}
+
+// auditKey returns the key of the origin in audit log addresses, the
+// resource ID with the IP address or the hostname.
+func (m *l7originStandaloneResourceModel) auditKey() string {
+	host := m.IP.ValueString()
+	if !m.Hostname.IsNull() {
+		host = m.Hostname.ValueString()
+	}
+
+	return strconv.FormatInt(m.L7ResourceID.ValueInt64(), 10) + "/" + host
+}
+
+// ips returns IP addresses of the origin.
+func (m *l7originStandaloneResourceModel) ips() []string {
+	if m.Hostname.IsNull() {
+		return []string{m.IP.ValueString()}
+	}
+
+	return resolvedIPs(m.ResolvedIPs)
+}
+
+// resolve resolves the hostname if it was unknown at plan time.
+func (m *l7originStandaloneResourceModel) resolve(ctx context.Context, resolver hostResolver) error {
+	if m.Hostname.IsNull() {
+		m.ResolvedIPs = types.ListNull(types.StringType)
+		return nil
+	}
+
+	if !m.ResolvedIPs.IsUnknown() && !m.ResolvedIPs.IsNull() {
+		return nil
+	}
+
+	ips, err := resolveHostname(ctx, resolver, m.Hostname.ValueString())
+	if err != nil {
+		return err
+	}
+	m.ResolvedIPs = resolvedIPsValue(ips)
+
+	return nil
+}
+
+// setOrigins stores IDs of the origins and, for a hostname, their IPs.
+func (m *l7originStandaloneResourceModel) setOrigins(items []*l7origin.Item) {
+	ids := make([]attr.Value, 0, len(items))
+	ips := make([]string, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, types.Int64Value(item.ID))
+		ips = append(ips, item.IP)
+	}
+
+	m.OriginIDs = types.ListValueMust(types.Int64Type, ids)
+	if !m.Hostname.IsNull() {
+		m.ResolvedIPs = resolvedIPsValue(ips)
+	}
+}
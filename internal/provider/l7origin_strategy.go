@@ -0,0 +1,420 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+
+	v1 "terraform-provider-servicepipe/internal/pkg/sdkv1"
+	l7origin "terraform-provider-servicepipe/internal/pkg/sdkv1/l7origin"
+)
+
+const (
+	// originUpdateStrategyDefault creates new origins, deletes missing ones and
+	// then updates the rest, as soon as each call can be made.
+	originUpdateStrategyDefault = "default"
+
+	// originUpdateStrategyAddFirst adds new origins as backups, updates the kept
+	// ones, promotes the new origins and deletes the missing ones last.
+	originUpdateStrategyAddFirst = "add_first"
+
+	// originUpdateStrategyWeightedShift adds new origins with zero weight and
+	// moves the weights towards the plan in steps before deleting the missing
+	// origins.
+	originUpdateStrategyWeightedShift = "weighted_shift"
+
+	// defaultOriginShiftSteps is the default number of weight steps used by the
+	// weighted_shift strategy.
+	defaultOriginShiftSteps = 4
+
+	// defaultOriginShiftPause is the default pause (in seconds) between weight
+	// steps of the weighted_shift strategy.
+	defaultOriginShiftPause = 30
+)
+
+// originUpdateOpts describes how origin changes are rolled out.
+type originUpdateOpts struct {
+	Strategy   string
+	ShiftSteps int64
+	ShiftPause time.Duration
+
+	// Parallelism is the maximum number of origin requests made at the same time.
+	Parallelism int
+}
+
+// originChanges is a set of origin operations needed to move the current origins
+// of a l7 resource to the desired ones. Origins are matched by IP.
+type originChanges struct {
+	// create contains desired origins that don't exist yet.
+	create []*l7origin.Item
+
+	// update contains existing origins with the desired weight and mode applied.
+	update []*l7origin.Item
+
+	// keep contains existing origins that already match the desired ones.
+	keep []*l7origin.Item
+
+	// delete contains existing origins that are not desired anymore.
+	delete []*l7origin.Item
+
+	// current contains existing origins by IP before any change.
+	current map[string]*l7origin.Item
+}
+
+func newOriginUpdateOpts(model *l7resourceResourceModel, parallelism int) originUpdateOpts {
+	opts := originUpdateOpts{
+		Strategy:    model.OriginUpdateStrategy.ValueString(),
+		ShiftSteps:  model.OriginShiftSteps.ValueInt64(),
+		ShiftPause:  time.Duration(model.OriginShiftPause.ValueInt64()) * time.Second,
+		Parallelism: parallelism,
+	}
+	if opts.Strategy == "" {
+		opts.Strategy = originUpdateStrategyDefault
+	}
+	if opts.ShiftSteps < 1 {
+		opts.ShiftSteps = 1
+	}
+
+	return opts
+}
+
+func diffOrigins(current, desired []*l7origin.Item) *originChanges {
+	changes := &originChanges{
+		current: make(map[string]*l7origin.Item, len(current)),
+	}
+	for _, item := range current {
+		changes.current[item.IP] = item
+	}
+
+	desiredIPs := make(map[string]bool, len(desired))
+	for _, item := range desired {
+		desiredIPs[item.IP] = true
+
+		existing, ok := changes.current[item.IP]
+		if !ok {
+			changes.create = append(changes.create, item)
+			continue
+		}
+
+		if existing.Weight == item.Weight && existing.Mode == item.Mode {
+			changes.keep = append(changes.keep, existing)
+			continue
+		}
+
+		updated := *existing
+		updated.Weight = item.Weight
+		updated.Mode = item.Mode
+		changes.update = append(changes.update, &updated)
+	}
+
+	for _, item := range current {
+		if !desiredIPs[item.IP] {
+			changes.delete = append(changes.delete, item)
+		}
+	}
+
+	return changes
+}
+
+// applyOriginChanges moves the origins of the l7 resource from current to desired
+// using the requested strategy. Independent origin requests of every stage of
+// the strategy run in parallel, a stage that fails stops the rollout. It returns
+// the resulting origins by IP.
+func applyOriginChanges(ctx context.Context, client *v1.Client, l7ResourceID int64, current, desired []*l7origin.Item, opts originUpdateOpts) (map[string]*l7origin.Item, error) {
+	changes := diffOrigins(current, desired)
+
+	tflog.Debug(ctx, "Applying l7 origin changes", map[string]any{
+		"strategy": opts.Strategy,
+		"create":   len(changes.create),
+		"update":   len(changes.update),
+		"delete":   len(changes.delete),
+	})
+
+	result := make(map[string]*l7origin.Item, len(desired))
+	for _, item := range changes.keep {
+		result[item.IP] = item
+	}
+
+	var err error
+	switch opts.Strategy {
+	case originUpdateStrategyAddFirst:
+		err = applyOriginChangesAddFirst(ctx, client, l7ResourceID, changes, result, opts)
+	case originUpdateStrategyWeightedShift:
+		err = applyOriginChangesWeightedShift(ctx, client, l7ResourceID, changes, result, opts)
+	default:
+		err = applyOriginChangesDefault(ctx, client, l7ResourceID, changes, result, opts)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func applyOriginChangesDefault(ctx context.Context, client *v1.Client, l7ResourceID int64, changes *originChanges, result map[string]*l7origin.Item, opts originUpdateOpts) error {
+	created, err := createOrigins(ctx, client, l7ResourceID, changes.create, "", opts.Parallelism)
+	if err != nil {
+		return err
+	}
+	setOriginResults(result, created)
+
+	if err := deleteOrigins(ctx, client, l7ResourceID, changes.delete, opts.Parallelism); err != nil {
+		return err
+	}
+
+	updated, err := updateOrigins(ctx, client, l7ResourceID, changes.current, changes.update, opts.Parallelism)
+	if err != nil {
+		return err
+	}
+	setOriginResults(result, updated)
+
+	return nil
+}
+
+func applyOriginChangesAddFirst(ctx context.Context, client *v1.Client, l7ResourceID int64, changes *originChanges, result map[string]*l7origin.Item, opts originUpdateOpts) error {
+	// New origins join as backups, so they don't receive traffic before
+	// the kept origins have their final settings.
+	created, err := createOrigins(ctx, client, l7ResourceID, changes.create, l7origin.ModeBackup, opts.Parallelism)
+	if err != nil {
+		return err
+	}
+	setOriginResults(result, created)
+
+	var promote []*l7origin.Item
+	for i, item := range changes.create {
+		// Promoted origins are updated from their created state.
+		changes.current[created[i].IP] = created[i]
+		if item.Mode != l7origin.ModeBackup {
+			promoted := *created[i]
+			promoted.Mode = item.Mode
+			promoted.Weight = item.Weight
+			promote = append(promote, &promoted)
+		}
+	}
+
+	updated, err := updateOrigins(ctx, client, l7ResourceID, changes.current, append(changes.update, promote...), opts.Parallelism)
+	if err != nil {
+		return err
+	}
+	setOriginResults(result, updated)
+
+	return deleteOrigins(ctx, client, l7ResourceID, changes.delete, opts.Parallelism)
+}
+
+func applyOriginChangesWeightedShift(ctx context.Context, client *v1.Client, l7ResourceID int64, changes *originChanges, result map[string]*l7origin.Item, opts originUpdateOpts) error {
+	type shift struct {
+		item     *l7origin.Item
+		from, to int64
+	}
+
+	zeroWeight := make([]*l7origin.Item, 0, len(changes.create))
+	for _, item := range changes.create {
+		start := *item
+		start.Weight = l7origin.MinWeight
+		zeroWeight = append(zeroWeight, &start)
+	}
+
+	created, err := createOrigins(ctx, client, l7ResourceID, zeroWeight, "", opts.Parallelism)
+	if err != nil {
+		return err
+	}
+	setOriginResults(result, created)
+
+	var shifts []shift
+	for i, item := range changes.create {
+		shifts = append(shifts, shift{item: created[i], from: l7origin.MinWeight, to: item.Weight})
+	}
+
+	// Mode switches can't be shifted gradually, apply them with the current
+	// weight before the first step.
+	var switches []*l7origin.Item
+	for _, item := range changes.update {
+		existing := changes.current[item.IP]
+		if existing.Mode != item.Mode {
+			switched := *existing
+			switched.Mode = item.Mode
+			switches = append(switches, &switched)
+		}
+	}
+
+	switched, err := updateOrigins(ctx, client, l7ResourceID, changes.current, switches, opts.Parallelism)
+	if err != nil {
+		return err
+	}
+	for _, item := range switched {
+		changes.current[item.IP] = item
+	}
+
+	for _, item := range changes.update {
+		existing := changes.current[item.IP]
+		result[item.IP] = existing
+		shifts = append(shifts, shift{item: existing, from: existing.Weight, to: item.Weight})
+	}
+
+	for _, item := range changes.delete {
+		shifts = append(shifts, shift{item: item, from: item.Weight, to: l7origin.MinWeight})
+	}
+
+	for step := int64(1); step <= opts.ShiftSteps; step++ {
+		err := runParallel(ctx, opts.Parallelism, len(shifts), func(ctx context.Context, i int) error {
+			s := shifts[i]
+			weight := s.from + (s.to-s.from)*step/opts.ShiftSteps
+			if weight == s.item.Weight {
+				return nil
+			}
+
+			next := *s.item
+			next.Weight = weight
+			updated, err := updateOrigin(ctx, client, l7ResourceID, s.item, &next)
+			if err != nil {
+				return err
+			}
+			*s.item = *updated
+
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		if step == opts.ShiftSteps {
+			break
+		}
+
+		tflog.Debug(ctx, "Waiting before the next l7 origin weight step", map[string]any{
+			"step":  step,
+			"pause": opts.ShiftPause.String(),
+		})
+		if err := sleepContext(ctx, opts.ShiftPause); err != nil {
+			return err
+		}
+	}
+
+	return deleteOrigins(ctx, client, l7ResourceID, changes.delete, opts.Parallelism)
+}
+
+// setOriginResults stores origins in the result by IP.
+func setOriginResults(result map[string]*l7origin.Item, items []*l7origin.Item) {
+	for _, item := range items {
+		result[item.IP] = item
+	}
+}
+
+// createOrigins creates origins in parallel, mode overrides the origin modes
+// when set. It returns the created origins in the order of items.
+func createOrigins(ctx context.Context, client *v1.Client, l7ResourceID int64, items []*l7origin.Item, mode string, parallelism int) ([]*l7origin.Item, error) {
+	created := make([]*l7origin.Item, len(items))
+	err := runParallel(ctx, parallelism, len(items), func(ctx context.Context, i int) error {
+		itemMode := items[i].Mode
+		if mode != "" {
+			itemMode = mode
+		}
+
+		item, err := createOrigin(ctx, client, l7ResourceID, items[i].IP, items[i].Weight, itemMode)
+		if err != nil {
+			return err
+		}
+		created[i] = item
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return created, nil
+}
+
+func createOrigin(ctx context.Context, client *v1.Client, l7ResourceID int64, ip string, weight int64, mode string) (*l7origin.Item, error) {
+	createOriginOpts := &l7origin.CreateOpts{
+		L7ResourceID: l7ResourceID,
+		IP:           ip,
+		Weight:       weight,
+		Mode:         mode,
+	}
+
+	result, _, err := l7origin.Create(ctx, client, createOriginOpts)
+	if err != nil {
+		return nil, fmt.Errorf("could not create l7 origin %s: %w", ip, err)
+	}
+
+	item := &result.Data.Result
+	item.L7ResourceID = l7ResourceID
+
+	return item, nil
+}
+
+// updateOrigins updates origins in parallel from the previous origins by IP. It
+// returns the updated origins in the order of items.
+func updateOrigins(ctx context.Context, client *v1.Client, l7ResourceID int64, previous map[string]*l7origin.Item, items []*l7origin.Item, parallelism int) ([]*l7origin.Item, error) {
+	updated := make([]*l7origin.Item, len(items))
+	err := runParallel(ctx, parallelism, len(items), func(ctx context.Context, i int) error {
+		item, err := updateOrigin(ctx, client, l7ResourceID, previous[items[i].IP], items[i])
+		if err != nil {
+			return err
+		}
+		updated[i] = item
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return updated, nil
+}
+
+// updateOrigin updates the origin, previous is the origin before the update
+// recorded in the audit log.
+func updateOrigin(ctx context.Context, client *v1.Client, l7ResourceID int64, previous, item *l7origin.Item) (*l7origin.Item, error) {
+	item.L7ResourceID = l7ResourceID
+
+	result, _, err := l7origin.UpdateFrom(ctx, client, previous, item)
+	if err != nil {
+		return nil, fmt.Errorf("could not update l7 origin %s: %w", item.IP, err)
+	}
+
+	updated := &result.Data.Result
+	updated.L7ResourceID = l7ResourceID
+
+	return updated, nil
+}
+
+// deleteOrigins deletes origins in parallel.
+func deleteOrigins(ctx context.Context, client *v1.Client, l7ResourceID int64, items []*l7origin.Item, parallelism int) error {
+	return runParallel(ctx, parallelism, len(items), func(ctx context.Context, i int) error {
+		item := items[i]
+		deleteOriginOpts := &l7origin.DeleteOpts{
+			ID:           item.ID,
+			L7ResourceID: l7ResourceID,
+		}
+
+		result, _, err := l7origin.Delete(ctx, client, deleteOriginOpts)
+		if err != nil {
+			return fmt.Errorf("could not delete l7 origin %s: %w", item.IP, err)
+		}
+		if result.Data.Result != "ok" {
+			return fmt.Errorf("could not delete l7 origin %s: got %q result", item.IP, result.Data.Result)
+		}
+
+		return nil
+	})
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
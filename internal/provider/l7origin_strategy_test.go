@@ -0,0 +1,212 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+
+	v1 "terraform-provider-servicepipe/internal/pkg/sdkv1"
+	"terraform-provider-servicepipe/internal/pkg/sdkv1/l7origin"
+)
+
+// fakeOriginAPI serves the origin calls of the strategies and records them in
+// the order they were made.
+type fakeOriginAPI struct {
+	mu      sync.Mutex
+	origins map[int64]l7origin.Item
+	nextID  int64
+	calls   []string
+}
+
+// newFakeOriginAPI returns a client of a fake API holding the origins.
+func newFakeOriginAPI(t *testing.T, origins []*l7origin.Item) (*fakeOriginAPI, *v1.Client) {
+	t.Helper()
+
+	api := &fakeOriginAPI{origins: make(map[int64]l7origin.Item)}
+	for _, origin := range origins {
+		api.origins[origin.ID] = *origin
+		api.nextID = max(api.nextID, origin.ID)
+	}
+
+	server := httptest.NewServer(api)
+	t.Cleanup(server.Close)
+
+	return api, v1.NewClientV1WithCustomHTTP(server.Client(), "token", server.URL)
+}
+
+func (api *fakeOriginAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+
+	var response any
+	switch r.Method {
+	case http.MethodPost:
+		var opts l7origin.CreateOpts
+		if err := json.NewDecoder(r.Body).Decode(&opts); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		api.nextID++
+		item := l7origin.Item{ID: api.nextID, IP: opts.IP, Weight: opts.Weight, Mode: opts.Mode}
+		api.origins[item.ID] = item
+		api.calls = append(api.calls, fmt.Sprintf("create %s %d %s", item.IP, item.Weight, item.Mode))
+		response = l7origin.Data{Data: l7origin.Result{Result: item}}
+	case http.MethodPut:
+		var item l7origin.Item
+		if err := json.NewDecoder(r.Body).Decode(&item); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		// The API does not return the resource identifier of origins.
+		item.L7ResourceID = 0
+		api.origins[item.ID] = item
+		api.calls = append(api.calls, fmt.Sprintf("update %s %d %s", item.IP, item.Weight, item.Mode))
+		response = l7origin.Data{Data: l7origin.Result{Result: item}}
+	case http.MethodDelete:
+		var opts l7origin.DeleteOpts
+		if err := json.NewDecoder(r.Body).Decode(&opts); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		api.calls = append(api.calls, fmt.Sprintf("delete %s", api.origins[opts.ID].IP))
+		delete(api.origins, opts.ID)
+		response = l7origin.DataDelete{Data: l7origin.ResultDelete{Result: "ok"}}
+	default:
+		http.Error(w, "unexpected method", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// originsByIP returns the weight and mode of the origins of the API by IP.
+func (api *fakeOriginAPI) originsByIP() map[string]string {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+
+	origins := make(map[string]string, len(api.origins))
+	for _, origin := range api.origins {
+		origins[origin.IP] = fmt.Sprintf("%d %s", origin.Weight, origin.Mode)
+	}
+
+	return origins
+}
+
+func TestApplyOriginChanges(t *testing.T) {
+	// 198.51.100.1 changes its weight, 198.51.100.2 is deleted, 198.51.100.3
+	// is created and 198.51.100.4 switches from backup to primary.
+	current := []*l7origin.Item{
+		{ID: 1, IP: "198.51.100.1", Weight: 60, Mode: l7origin.ModePrimary},
+		{ID: 2, IP: "198.51.100.2", Weight: 40, Mode: l7origin.ModePrimary},
+		{ID: 4, IP: "198.51.100.4", Weight: 10, Mode: l7origin.ModeBackup},
+	}
+	desired := []*l7origin.Item{
+		{IP: "198.51.100.1", Weight: 20, Mode: l7origin.ModePrimary},
+		{IP: "198.51.100.3", Weight: 80, Mode: l7origin.ModePrimary},
+		{IP: "198.51.100.4", Weight: 10, Mode: l7origin.ModePrimary},
+	}
+	wantOrigins := map[string]string{
+		"198.51.100.1": "20 primary",
+		"198.51.100.3": "80 primary",
+		"198.51.100.4": "10 primary",
+	}
+
+	tests := map[string]struct {
+		opts      originUpdateOpts
+		wantCalls []string
+	}{
+		"default": {
+			opts: originUpdateOpts{Strategy: originUpdateStrategyDefault, ShiftSteps: 1},
+			wantCalls: []string{
+				"create 198.51.100.3 80 primary",
+				"delete 198.51.100.2",
+				"update 198.51.100.1 20 primary",
+				"update 198.51.100.4 10 primary",
+			},
+		},
+		"add_first": {
+			opts: originUpdateOpts{Strategy: originUpdateStrategyAddFirst, ShiftSteps: 1},
+			wantCalls: []string{
+				"create 198.51.100.3 80 backup",
+				"update 198.51.100.1 20 primary",
+				"update 198.51.100.4 10 primary",
+				"update 198.51.100.3 80 primary",
+				"delete 198.51.100.2",
+			},
+		},
+		"weighted_shift": {
+			opts: originUpdateOpts{Strategy: originUpdateStrategyWeightedShift, ShiftSteps: 2},
+			wantCalls: []string{
+				"create 198.51.100.3 0 primary",
+				"update 198.51.100.4 10 primary",
+				"update 198.51.100.3 40 primary",
+				"update 198.51.100.1 40 primary",
+				"update 198.51.100.2 20 primary",
+				"update 198.51.100.3 80 primary",
+				"update 198.51.100.1 20 primary",
+				"update 198.51.100.2 0 primary",
+				"delete 198.51.100.2",
+			},
+		},
+		"weighted_shift in a single step": {
+			opts: originUpdateOpts{Strategy: originUpdateStrategyWeightedShift, ShiftSteps: 1},
+			wantCalls: []string{
+				"create 198.51.100.3 0 primary",
+				"update 198.51.100.4 10 primary",
+				"update 198.51.100.3 80 primary",
+				"update 198.51.100.1 20 primary",
+				"update 198.51.100.2 0 primary",
+				"delete 198.51.100.2",
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			api, client := newFakeOriginAPI(t, current)
+
+			// A single request at a time keeps the calls in a stable order.
+			test.opts.Parallelism = 1
+			result, err := applyOriginChanges(context.Background(), client, 10, copyOrigins(current), copyOrigins(desired), test.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(api.calls, test.wantCalls) {
+				t.Errorf("got calls:\n%q\nwant:\n%q", api.calls, test.wantCalls)
+			}
+			if got := api.originsByIP(); !reflect.DeepEqual(got, wantOrigins) {
+				t.Errorf("got origins %v, want %v", got, wantOrigins)
+			}
+
+			got := make(map[string]string, len(result))
+			for ip, item := range result {
+				got[ip] = fmt.Sprintf("%d %s", item.Weight, item.Mode)
+			}
+			if !reflect.DeepEqual(got, wantOrigins) {
+				t.Errorf("got resulting origins %v, want %v", got, wantOrigins)
+			}
+		})
+	}
+}
+
+// copyOrigins returns copies of the origins, strategies update them in place.
+func copyOrigins(origins []*l7origin.Item) []*l7origin.Item {
+	copies := make([]*l7origin.Item, 0, len(origins))
+	for _, origin := range origins {
+		origin := *origin
+		copies = append(copies, &origin)
+	}
+
+	return copies
+}
@@ -0,0 +1,98 @@
+package provider
+
+import (
+	"sort"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	l7origin "terraform-provider-servicepipe/internal/pkg/sdkv1/l7origin"
+)
+
+// autoWeightTotal is the sum of weights auto_weight distributes across primary
+// origins and, separately, across backup origins.
+const autoWeightTotal = l7origin.MaxWeight
+
+// applyAutoWeights sets origin weights proportionally to their weight_ratio.
+// Every resolved IP of a hostname counts as a separate origin. Weights become
+// unknown when a mode, a ratio or resolved IPs are not known yet.
+func applyAutoWeights(origins []*l7originResourceModel) {
+	for _, origin := range origins {
+		if origin.Mode.IsUnknown() || origin.WeightRatio.IsUnknown() ||
+			(!origin.Hostname.IsNull() && origin.ResolvedIPs.IsUnknown()) {
+			for _, o := range origins {
+				o.Weight = types.Int64Unknown()
+			}
+			return
+		}
+	}
+
+	groups := make(map[string][]*l7originResourceModel)
+	for _, origin := range origins {
+		mode := origin.Mode.ValueString()
+		groups[mode] = append(groups[mode], origin)
+	}
+
+	for _, group := range groups {
+		balanceOriginWeights(group)
+	}
+}
+
+// balanceOriginWeights splits autoWeightTotal across the origins of one mode.
+func balanceOriginWeights(origins []*l7originResourceModel) {
+	type share struct {
+		origin    *l7originResourceModel
+		ips       int64
+		weight    int64
+		remainder int64
+	}
+
+	var units int64
+	shares := make([]*share, 0, len(origins))
+	for _, origin := range origins {
+		ratio := int64(1)
+		if !origin.WeightRatio.IsNull() {
+			ratio = origin.WeightRatio.ValueInt64()
+		}
+
+		ips := int64(1)
+		if !origin.Hostname.IsNull() {
+			ips = int64(len(resolvedIPs(origin.ResolvedIPs)))
+		}
+
+		units += ratio * ips
+		shares = append(shares, &share{origin: origin, ips: ips, weight: ratio})
+	}
+
+	if units == 0 {
+		for _, s := range shares {
+			s.origin.Weight = types.Int64Value(0)
+		}
+		return
+	}
+
+	// Weights are rounded down, the rest is given to origins with the largest
+	// remainders.
+	rest := int64(autoWeightTotal)
+	for _, s := range shares {
+		ratio := s.weight
+		s.weight = autoWeightTotal * ratio / units
+		s.remainder = autoWeightTotal * ratio % units
+		rest -= s.weight * s.ips
+	}
+
+	sorted := make([]*share, len(shares))
+	copy(sorted, shares)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].remainder > sorted[j].remainder
+	})
+	for _, s := range sorted {
+		if s.remainder > 0 && s.ips > 0 && s.ips <= rest {
+			s.weight++
+			rest -= s.ips
+		}
+	}
+
+	for _, s := range shares {
+		s.origin.Weight = types.Int64Value(s.weight)
+	}
+}
@@ -0,0 +1,178 @@
+package provider
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"terraform-provider-servicepipe/internal/pkg/sdkv1/l7origin"
+)
+
+// newWeightTestModel returns a model of an IP origin with the weight ratio, a
+// negative ratio leaves it null.
+func newWeightTestModel(ratio int64, mode string) *l7originResourceModel {
+	model := newOriginTestModel(types.StringValue("198.51.100.1"), types.StringNull(), 0, mode)
+	model.Weight = types.Int64Unknown()
+	if ratio >= 0 {
+		model.WeightRatio = types.Int64Value(ratio)
+	}
+
+	return model
+}
+
+// newHostnameWeightTestModel returns a model of a hostname origin resolved to
+// the number of IPs with the weight ratio.
+func newHostnameWeightTestModel(ratio int64, ips int) *l7originResourceModel {
+	resolved := make([]string, 0, ips)
+	for i := 0; i < ips; i++ {
+		resolved = append(resolved, "203.0.113."+strconv.Itoa(i+1))
+	}
+
+	model := newOriginTestModel(types.StringNull(), types.StringValue("origin.example.com"), 0, l7origin.ModePrimary)
+	model.Weight = types.Int64Unknown()
+	model.WeightRatio = types.Int64Value(ratio)
+	model.ResolvedIPs = resolvedIPsValue(resolved)
+
+	return model
+}
+
+func TestBalanceOriginWeights(t *testing.T) {
+	tests := map[string]struct {
+		origins []*l7originResourceModel
+		want    []int64
+		wantSum int64
+	}{
+		"single origin": {
+			origins: []*l7originResourceModel{newWeightTestModel(1, l7origin.ModePrimary)},
+			want:    []int64{100},
+			wantSum: 100,
+		},
+		"null ratios count as one": {
+			origins: []*l7originResourceModel{
+				newWeightTestModel(-1, l7origin.ModePrimary),
+				newWeightTestModel(-1, l7origin.ModePrimary),
+			},
+			want:    []int64{50, 50},
+			wantSum: 100,
+		},
+		"remainder to the first of equal remainders": {
+			origins: []*l7originResourceModel{
+				newWeightTestModel(1, l7origin.ModePrimary),
+				newWeightTestModel(1, l7origin.ModePrimary),
+				newWeightTestModel(1, l7origin.ModePrimary),
+			},
+			want:    []int64{34, 33, 33},
+			wantSum: 100,
+		},
+		"remainder to the largest remainder": {
+			origins: []*l7originResourceModel{
+				newWeightTestModel(1, l7origin.ModePrimary),
+				newWeightTestModel(2, l7origin.ModePrimary),
+			},
+			want:    []int64{33, 67},
+			wantSum: 100,
+		},
+		"remainders to several origins": {
+			origins: []*l7originResourceModel{
+				newWeightTestModel(3, l7origin.ModePrimary),
+				newWeightTestModel(3, l7origin.ModePrimary),
+				newWeightTestModel(1, l7origin.ModePrimary),
+			},
+			want:    []int64{43, 43, 14},
+			wantSum: 100,
+		},
+		"remainders of six origins": {
+			origins: []*l7originResourceModel{
+				newWeightTestModel(1, l7origin.ModePrimary),
+				newWeightTestModel(1, l7origin.ModePrimary),
+				newWeightTestModel(1, l7origin.ModePrimary),
+				newWeightTestModel(1, l7origin.ModePrimary),
+				newWeightTestModel(1, l7origin.ModePrimary),
+				newWeightTestModel(1, l7origin.ModePrimary),
+			},
+			want:    []int64{17, 17, 17, 17, 16, 16},
+			wantSum: 100,
+		},
+		"hostname counts every resolved ip": {
+			origins: []*l7originResourceModel{
+				newHostnameWeightTestModel(1, 2),
+				newWeightTestModel(1, l7origin.ModePrimary),
+			},
+			want:    []int64{33, 34},
+			wantSum: 100,
+		},
+		"remainder smaller than the resolved ips": {
+			origins: []*l7originResourceModel{newHostnameWeightTestModel(1, 3)},
+			want:    []int64{33},
+			wantSum: 99,
+		},
+		"zero ratios": {
+			origins: []*l7originResourceModel{
+				newWeightTestModel(0, l7origin.ModePrimary),
+				newWeightTestModel(0, l7origin.ModePrimary),
+			},
+			want:    []int64{0, 0},
+			wantSum: 0,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			balanceOriginWeights(test.origins)
+
+			var sum int64
+			for i, origin := range test.origins {
+				if !origin.Weight.Equal(types.Int64Value(test.want[i])) {
+					t.Errorf("got weight %s of origin %d, want %d", origin.Weight, i, test.want[i])
+				}
+
+				ips := int64(1)
+				if !origin.Hostname.IsNull() {
+					ips = int64(len(resolvedIPs(origin.ResolvedIPs)))
+				}
+				sum += origin.Weight.ValueInt64() * ips
+			}
+
+			if sum != test.wantSum {
+				t.Errorf("got weights summing to %d, want %d", sum, test.wantSum)
+			}
+		})
+	}
+}
+
+func TestApplyAutoWeights(t *testing.T) {
+	t.Run("modes are balanced separately", func(t *testing.T) {
+		origins := []*l7originResourceModel{
+			newWeightTestModel(1, l7origin.ModePrimary),
+			newWeightTestModel(1, l7origin.ModeBackup),
+			newWeightTestModel(1, l7origin.ModePrimary),
+			newWeightTestModel(3, l7origin.ModeBackup),
+		}
+
+		applyAutoWeights(origins)
+
+		want := []int64{50, 25, 50, 75}
+		for i, origin := range origins {
+			if !origin.Weight.Equal(types.Int64Value(want[i])) {
+				t.Errorf("got weight %s of origin %d, want %d", origin.Weight, i, want[i])
+			}
+		}
+	})
+
+	t.Run("unknown ratio", func(t *testing.T) {
+		origins := []*l7originResourceModel{
+			newWeightTestModel(1, l7origin.ModePrimary),
+			newWeightTestModel(1, l7origin.ModePrimary),
+		}
+		origins[1].WeightRatio = types.Int64Unknown()
+
+		applyAutoWeights(origins)
+
+		for i, origin := range origins {
+			if !origin.Weight.IsUnknown() {
+				t.Errorf("got weight %s of origin %d, want unknown", origin.Weight, i)
+			}
+		}
+	})
+}
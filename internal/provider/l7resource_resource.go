@@ -2,17 +2,29 @@ package provider
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-	"log"
+	"sort"
 	"strconv"
 	"time"
 
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/setvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64default"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/setdefault"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 
 	v1 "terraform-provider-servicepipe/internal/pkg/sdkv1"
 	l7origin "terraform-provider-servicepipe/internal/pkg/sdkv1/l7origin"
@@ -21,45 +33,69 @@ import (
 
 // Ensure the implementation satisfies the expected interfaces.
 var (
-	_ resource.Resource              = &l7resourceResource{}
-	_ resource.ResourceWithConfigure = &l7resourceResource{}
+	_ resource.Resource                     = &l7resourceResource{}
+	_ resource.ResourceWithConfigure        = &l7resourceResource{}
+	_ resource.ResourceWithConfigValidators = &l7resourceResource{}
+	_ resource.ResourceWithImportState      = &l7resourceResource{}
+	_ resource.ResourceWithModifyPlan       = &l7resourceResource{}
+	_ resource.ResourceWithUpgradeState     = &l7resourceResource{}
 )
 
 // Newl7resourceResource is a helper function to simplify the provider implementation.
 func NewL7resourceResource() resource.Resource {
-	return &l7resourceResource{}
+	return &l7resourceResource{
+		resolver: newNetHostResolver(),
+	}
 }
 
 // l7resourceResource is the resource implementation.
 type l7resourceResource struct {
 	client *v1.Client
+
+	// deletionProtection is the deletion_protection default of new resources.
+	deletionProtection bool
+
+	// maxParallelRequests limits origin requests made at the same time.
+	maxParallelRequests int
+
+	// resolver resolves origin hostnames.
+	resolver hostResolver
 }
 
 // l7resourceResourceModel maps the resource schema data.
 type l7resourceResourceModel struct {
 	L7ResourceID          types.Int64  `tfsdk:"l7_resource_id"`
 	L7ResourceName        types.String `tfsdk:"l7_resource_name"`
-	L7ResourceIsActive    types.Int64  `tfsdk:"l7_resource_is_active"`
-	L7ProtectionDisable   types.Int64  `tfsdk:"l7_protection_disable"`
-	UseCustomSsl          types.Int64  `tfsdk:"use_custom_ssl"`
-	UseLetsencryptSsl     types.Int64  `tfsdk:"use_letsencrypt_ssl"`
+	L7ResourceNameASCII   types.String `tfsdk:"l7_resource_name_ascii"`
+	L7ResourceNameUnicode types.String `tfsdk:"l7_resource_name_unicode"`
+	L7ResourceIsActive    types.Bool   `tfsdk:"l7_resource_is_active"`
+	L7ProtectionDisable   types.Bool   `tfsdk:"l7_protection_disable"`
+	UseCustomSsl          types.Bool   `tfsdk:"use_custom_ssl"`
+	UseLetsencryptSsl     types.Bool   `tfsdk:"use_letsencrypt_ssl"`
 	CustomSslKey          types.String `tfsdk:"custom_ssl_key"`
 	CustomSslCrt          types.String `tfsdk:"custom_ssl_crt"`
-	Forcessl              types.Int64  `tfsdk:"force_ssl"`
-	ServiceHTTP2          types.Int64  `tfsdk:"service_http2"`
-	GeoipMode             types.Int64  `tfsdk:"geoip_mode"`
-	GeoipList             types.String `tfsdk:"geoip_list"`
-	GlobalWhitelistActive types.Int64  `tfsdk:"global_whitelist_active"`
-	HTTP2https            types.Int64  `tfsdk:"http_2_https"`
-	HTTPS2http            types.Int64  `tfsdk:"https_2_http"`
+	Forcessl              types.Bool   `tfsdk:"force_ssl"`
+	ServiceHTTP2          types.Bool   `tfsdk:"service_http2"`
+	GeoipMode             types.String `tfsdk:"geoip_mode"`
+	GeoipList             types.Set    `tfsdk:"geoip_list"`
+	GlobalWhitelistActive types.Bool   `tfsdk:"global_whitelist_active"`
+	HTTP2https            types.Bool   `tfsdk:"http_2_https"`
+	HTTPS2http            types.Bool   `tfsdk:"https_2_http"`
 	ProtectedIp           types.String `tfsdk:"protected_ip"`
-	Wwwredir              types.Int64  `tfsdk:"www_redir"`
-	Cdn                   types.Int64  `tfsdk:"cdn"`
+	Wwwredir              types.Bool   `tfsdk:"www_redir"`
+	Cdn                   types.Bool   `tfsdk:"cdn"`
 	CdnHost               types.String `tfsdk:"cdn_host"`
 	CdnProxyHost          types.String `tfsdk:"cdn_proxy_host"`
+	ModifiedAt            types.Int64  `tfsdk:"modified_at"`
 
 	Origins []*l7originResourceModel `tfsdk:"origins"`
 
+	OriginUpdateStrategy types.String `tfsdk:"origin_update_strategy"`
+	OriginShiftSteps     types.Int64  `tfsdk:"origin_shift_steps"`
+	OriginShiftPause     types.Int64  `tfsdk:"origin_shift_pause"`
+	AutoWeight           types.Bool   `tfsdk:"auto_weight"`
+	DeletionProtection   types.Bool   `tfsdk:"deletion_protection"`
+
 	LastUpdated types.String `tfsdk:"last_updated"`
 }
 
@@ -67,8 +103,11 @@ type l7originResourceModel struct {
 	L7ResourceID types.Int64  `tfsdk:"l7_resource_id"`
 	ID           types.Int64  `tfsdk:"id"`
 	Weight       types.Int64  `tfsdk:"weight"`
+	WeightRatio  types.Int64  `tfsdk:"weight_ratio"`
 	Mode         types.String `tfsdk:"mode"`
 	IP           types.String `tfsdk:"ip"`
+	Hostname     types.String `tfsdk:"hostname"`
+	ResolvedIPs  types.List   `tfsdk:"resolved_ips"`
 	CreatedAt    types.Int64  `tfsdk:"created_at"`
 	ModifiedAt   types.Int64  `tfsdk:"modified_at"`
 }
@@ -81,32 +120,59 @@ func (r *l7resourceResource) Metadata(_ context.Context, req resource.MetadataRe
 // Schema defines the schema for the resource.
 func (r *l7resourceResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = schema.Schema{
+		Version: 2,
+		MarkdownDescription: "Manages a domain protected by Servicepipe and its origins.\n\n" +
+			"The API doesn't rename domains, so changing `l7_resource_name` replaces the resource. " +
+			"Domains with different names can coexist, so `create_before_destroy` may be used to create the new domain " +
+			"before the old one is destroyed. Replacing a resource with `deletion_protection` enabled fails on the destroy.",
 		Attributes: map[string]schema.Attribute{
 			"l7_resource_id": schema.Int64Attribute{
 				Computed: true,
 			},
 			"l7_resource_name": schema.StringAttribute{
 				Required: true,
+				MarkdownDescription: "Domain name without `www.`, Unicode and punycode forms of internationalized names are equal, " +
+					"e.g. `пример.рф` and `xn--e1afmkfd.xn--p1ai`. Changing the name forces a new resource to be created.",
+				Validators: []validator.String{
+					isDomainName(),
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplaceIf(
+						func(_ context.Context, req planmodifier.StringRequest, resp *stringplanmodifier.RequiresReplaceIfFuncResponse) {
+							resp.RequiresReplace = !domainNamesEqual(req.PlanValue, req.StateValue)
+						},
+						"Changing the domain name forces a new resource to be created, equivalent forms of the name don't.",
+						"Changing the domain name forces a new resource to be created, equivalent forms of the name don't.",
+					),
+				},
+			},
+			"l7_resource_name_ascii": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "ASCII (punycode) form of `l7_resource_name`.",
 			},
-			"l7_resource_is_active": schema.Int64Attribute{
+			"l7_resource_name_unicode": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Unicode form of `l7_resource_name`.",
+			},
+			"l7_resource_is_active": schema.BoolAttribute{
 				Optional: true,
 				Computed: true,
-				Default:  int64default.StaticInt64(1),
+				Default:  booldefault.StaticBool(true),
 			},
-			"l7_protection_disable": schema.Int64Attribute{
+			"l7_protection_disable": schema.BoolAttribute{
 				Optional: true,
 				Computed: true,
-				Default:  int64default.StaticInt64(0),
+				Default:  booldefault.StaticBool(false),
 			},
-			"use_custom_ssl": schema.Int64Attribute{
+			"use_custom_ssl": schema.BoolAttribute{
 				Optional: true,
 				Computed: true,
-				Default:  int64default.StaticInt64(0),
+				Default:  booldefault.StaticBool(false),
 			},
-			"use_letsencrypt_ssl": schema.Int64Attribute{
+			"use_letsencrypt_ssl": schema.BoolAttribute{
 				Optional: true,
 				Computed: true,
-				Default:  int64default.StaticInt64(0),
+				Default:  booldefault.StaticBool(false),
 			},
 			"custom_ssl_key": schema.StringAttribute{
 				Optional: true,
@@ -118,53 +184,67 @@ func (r *l7resourceResource) Schema(_ context.Context, _ resource.SchemaRequest,
 				Computed: true,
 				Default:  stringdefault.StaticString(""),
 			},
-			"force_ssl": schema.Int64Attribute{
+			"force_ssl": schema.BoolAttribute{
 				Optional: true,
 				Computed: true,
-				Default:  int64default.StaticInt64(0),
+				Default:  booldefault.StaticBool(false),
 			},
-			"service_http2": schema.Int64Attribute{
+			"service_http2": schema.BoolAttribute{
 				Optional: true,
 				Computed: true,
-				Default:  int64default.StaticInt64(0),
+				Default:  booldefault.StaticBool(false),
 			},
-			"geoip_mode": schema.Int64Attribute{
+			"geoip_mode": schema.StringAttribute{
 				Optional: true,
 				Computed: true,
-				Default:  int64default.StaticInt64(0),
+				Default:  stringdefault.StaticString(geoipModeOff),
+				MarkdownDescription: "GeoIP filtering mode: `off`, `allow` to allow access only from countries of `geoip_list` " +
+					"or `deny` to deny access from them.",
+				Validators: []validator.String{
+					stringvalidator.OneOf(geoipModeOff, geoipModeAllow, geoipModeDeny),
+				},
 			},
-			"geoip_list": schema.StringAttribute{
-				Optional: true,
-				Computed: true,
-				Default:  stringdefault.StaticString(""),
+			"geoip_list": schema.SetAttribute{
+				Optional:            true,
+				Computed:            true,
+				ElementType:         types.StringType,
+				Default:             setdefault.StaticValue(types.SetValueMust(types.StringType, []attr.Value{})),
+				MarkdownDescription: "ISO 3166-1 alpha-2 country codes, such as `RU` or `US`, `geoip_mode` is applied to.",
+				Validators: []validator.Set{
+					setvalidator.ValueStringsAre(isCountryCode()),
+				},
 			},
-			"global_whitelist_active": schema.Int64Attribute{
+			"global_whitelist_active": schema.BoolAttribute{
 				Optional: true,
 				Computed: true,
-				Default:  int64default.StaticInt64(1),
+				Default:  booldefault.StaticBool(true),
 			},
-			"http_2_https": schema.Int64Attribute{
+			"http_2_https": schema.BoolAttribute{
 				Optional: true,
 				Computed: true,
-				Default:  int64default.StaticInt64(0),
+				Default:  booldefault.StaticBool(false),
 			},
-			"https_2_http": schema.Int64Attribute{
+			"https_2_http": schema.BoolAttribute{
 				Optional: true,
 				Computed: true,
-				Default:  int64default.StaticInt64(0),
+				Default:  booldefault.StaticBool(false),
 			},
 			"protected_ip": schema.StringAttribute{
 				Computed: true,
 			},
-			"www_redir": schema.Int64Attribute{
+			"modified_at": schema.Int64Attribute{
+				Computed:            true,
+				MarkdownDescription: "Unix timestamp of the last domain modification, updates fail when the domain was modified after the last refresh.",
+			},
+			"www_redir": schema.BoolAttribute{
 				Optional: true,
 				Computed: true,
-				Default:  int64default.StaticInt64(0),
+				Default:  booldefault.StaticBool(false),
 			},
-			"cdn": schema.Int64Attribute{
+			"cdn": schema.BoolAttribute{
 				Optional: true,
 				Computed: true,
-				Default:  int64default.StaticInt64(0),
+				Default:  booldefault.StaticBool(false),
 			},
 			"cdn_host": schema.StringAttribute{
 				Optional: true,
@@ -179,8 +259,57 @@ func (r *l7resourceResource) Schema(_ context.Context, _ resource.SchemaRequest,
 			"last_updated": schema.StringAttribute{
 				Computed: true,
 			},
+			"origin_update_strategy": schema.StringAttribute{
+				Optional: true,
+				Computed: true,
+				Default:  stringdefault.StaticString(originUpdateStrategyDefault),
+				Validators: []validator.String{
+					stringvalidator.OneOf(
+						originUpdateStrategyDefault,
+						originUpdateStrategyAddFirst,
+						originUpdateStrategyWeightedShift,
+					),
+				},
+				MarkdownDescription: "How origin changes are rolled out on update: `default` applies them as they come, " +
+					"`add_first` adds new origins as backups and deletes missing origins last, " +
+					"`weighted_shift` adds new origins with zero weight and shifts weights in steps before deleting missing origins.",
+			},
+			"origin_shift_steps": schema.Int64Attribute{
+				Optional: true,
+				Computed: true,
+				Default:  int64default.StaticInt64(defaultOriginShiftSteps),
+				Validators: []validator.Int64{
+					int64validator.AtLeast(1),
+				},
+				MarkdownDescription: "Number of weight steps used by the `weighted_shift` origin update strategy.",
+			},
+			"origin_shift_pause": schema.Int64Attribute{
+				Optional: true,
+				Computed: true,
+				Default:  int64default.StaticInt64(defaultOriginShiftPause),
+				Validators: []validator.Int64{
+					int64validator.AtLeast(0),
+				},
+				MarkdownDescription: "Pause in seconds between weight steps of the `weighted_shift` origin update strategy.",
+			},
+			"auto_weight": schema.BoolAttribute{
+				Optional: true,
+				Computed: true,
+				Default:  booldefault.StaticBool(false),
+				MarkdownDescription: "Compute origin weights so they sum up to 100 across primary origins and, separately, across backup origins. " +
+					"Weights are split equally or according to `weight_ratio` of origins.",
+			},
+			"deletion_protection": schema.BoolAttribute{
+				Optional: true,
+				Computed: true,
+				MarkdownDescription: "Fail to destroy the resource while enabled, set it to `false` in a separate apply before the destroy. " +
+					"Defaults to the provider `deletion_protection` for new resources and to `false` otherwise.",
+			},
 			"origins": schema.ListNestedAttribute{
 				Required: true,
+				Validators: []validator.List{
+					listvalidator.SizeAtLeast(1),
+				},
 				NestedObject: schema.NestedAttributeObject{
 					Attributes: map[string]schema.Attribute{
 						"l7_resource_id": schema.Int64Attribute{
@@ -193,16 +322,44 @@ func (r *l7resourceResource) Schema(_ context.Context, _ resource.SchemaRequest,
 							Optional: true,
 							Computed: true,
 							Default:  int64default.StaticInt64(50),
+							Validators: []validator.Int64{
+								int64validator.Between(l7origin.MinWeight, l7origin.MaxWeight),
+							},
+						},
+						"weight_ratio": schema.Int64Attribute{
+							Optional: true,
+							Validators: []validator.Int64{
+								int64validator.AtLeast(1),
+							},
+							MarkdownDescription: "Share of the origin in the computed weights when `auto_weight` is enabled, defaults to 1.",
 						},
 						"mode": schema.StringAttribute{
 							Optional: true,
 							Computed: true,
-							Default:  stringdefault.StaticString(""),
+							Default:  stringdefault.StaticString(l7origin.ModePrimary),
+							Validators: []validator.String{
+								stringvalidator.OneOf(l7origin.ModePrimary, l7origin.ModeBackup),
+							},
 						},
 						"ip": schema.StringAttribute{
 							Optional: true,
-							Computed: true,
-							Default:  stringdefault.StaticString(""),
+							Validators: []validator.String{
+								isIPAddress(),
+								stringvalidator.ExactlyOneOf(path.MatchRelative().AtParent().AtName("hostname")),
+							},
+						},
+						"hostname": schema.StringAttribute{
+							Optional: true,
+							Validators: []validator.String{
+								stringvalidator.LengthAtLeast(1),
+							},
+							MarkdownDescription: "Origin hostname resolved to A/AAAA records at plan time, " +
+								"one origin with the same weight and mode is created per resolved IP. Conflicts with `ip`.",
+						},
+						"resolved_ips": schema.ListAttribute{
+							Computed:            true,
+							ElementType:         types.StringType,
+							MarkdownDescription: "IP addresses the origin `hostname` was resolved to.",
 						},
 						"created_at": schema.Int64Attribute{
 							Computed: true,
@@ -217,41 +374,181 @@ func (r *l7resourceResource) Schema(_ context.Context, _ resource.SchemaRequest,
 	}
 }
 
+// ConfigValidators returns validators that check the resource configuration as a whole.
+func (r *l7resourceResource) ConfigValidators(_ context.Context) []resource.ConfigValidator {
+	return []resource.ConfigValidator{
+		l7originsValidator{},
+		l7originWeightsValidator{},
+		l7resourceSettingsValidator{},
+	}
+}
+
+// ModifyPlan computes the forms of the domain name, the deletion_protection
+// default and resolves origin hostnames, so the plan shows the resulting origin
+// IPs and DNS changes produce a diff. With auto_weight it also computes origin
+// weights.
+func (r *l7resourceResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// Nothing to resolve on destroy.
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var name types.String
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("l7_resource_name"), &name)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ascii, unicode := flattenDomainName(name)
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("l7_resource_name_ascii"), ascii)...)
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("l7_resource_name_unicode"), unicode)...)
+
+	var deletionProtection types.Bool
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("deletion_protection"), &deletionProtection)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if deletionProtection.IsNull() {
+		deletionProtection = types.BoolValue(r.deletionProtection)
+		if !req.State.Raw.IsNull() {
+			var prior types.Bool
+			resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("deletion_protection"), &prior)...)
+			deletionProtection = types.BoolValue(prior.ValueBool())
+		}
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("deletion_protection"), deletionProtection)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	var origins types.List
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("origins"), &origins)...)
+	if resp.Diagnostics.HasError() || origins.IsUnknown() {
+		return
+	}
+
+	var plan *l7resourceResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// The plan is written back as a whole, so values set above are set on the
+	// model too.
+	plan.L7ResourceNameASCII = ascii
+	plan.L7ResourceNameUnicode = unicode
+	plan.DeletionProtection = deletionProtection
+
+	var state *l7resourceResourceModel
+	if !req.State.Raw.IsNull() {
+		resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	changed := false
+	for i, origin := range plan.Origins {
+		switch {
+		case origin.Hostname.IsUnknown():
+			origin.ResolvedIPs = types.ListUnknown(types.StringType)
+		case origin.Hostname.IsNull():
+			origin.ResolvedIPs = types.ListNull(types.StringType)
+		default:
+			ips, err := resolveHostname(ctx, r.resolver, origin.Hostname.ValueString())
+			if err != nil {
+				resp.Diagnostics.AddAttributeError(
+					path.Root("origins").AtListIndex(i).AtName("hostname"),
+					"Error Resolving Origin Hostname",
+					err.Error(),
+				)
+				continue
+			}
+			origin.ResolvedIPs = resolvedIPsValue(ips)
+		}
+
+		if state != nil && !l7OriginResolvedIPsEqual(state.Origins, origin) {
+			changed = true
+		}
+	}
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(validateL7OriginIPsUnique(expandL7OriginModels(plan.Origins))...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if plan.AutoWeight.ValueBool() {
+		applyAutoWeights(plan.Origins)
+
+		if state != nil && !l7OriginWeightsEqual(state.Origins, plan.Origins) {
+			changed = true
+		}
+	}
+
+	if changed {
+		plan.LastUpdated = types.StringUnknown()
+	}
+
+	resp.Diagnostics.Append(resp.Plan.Set(ctx, &plan)...)
+}
+
 // Configure adds the provider configured client to the resource.
 func (r *l7resourceResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
 	if req.ProviderData == nil {
 		return
 	}
 
-	client, ok := req.ProviderData.(*v1.Client)
+	providerData, ok := req.ProviderData.(*servicepipeProviderData)
 
 	if !ok {
 		resp.Diagnostics.AddError(
 			"Unexpected Data Source Configure Type",
-			fmt.Sprintf("Expected *hashicups.Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+			fmt.Sprintf("Expected *servicepipeProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
 		)
 
 		return
 	}
 
-	r.client = client
+	r.client = providerData.client
+	r.deletionProtection = providerData.deletionProtection
+	r.maxParallelRequests = providerData.maxParallelRequests
 }
 
 // Create creates the resource and sets the initial Terraform state.
 func (r *l7resourceResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	ctx, span := startOperationSpan(ctx, "servicepipe_l7resource", "create")
+	defer func() { endOperationSpan(span, resp.Diagnostics) }()
+
 	var plan *l7resourceResourceModel
 	diags := req.Plan.Get(ctx, &plan)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
+	ctx = withAuditAddress(ctx, "servicepipe_l7resource", plan.L7ResourceName.ValueString())
 
 	planOrigins := plan.Origins
+	if err := resolveL7OriginModels(ctx, r.resolver, planOrigins); err != nil {
+		resp.Diagnostics.AddError(
+			"Error Resolving Origin Hostname",
+			err.Error(),
+		)
+		return
+	}
+
+	desiredOrigins := expandL7OriginModels(planOrigins)
+	primary := primaryL7Origin(desiredOrigins)
 
 	// Generate API request body from plan
 	createOpts := &l7resource.CreateOpts{
 		L7ResourceName: plan.L7ResourceName.ValueString(),
-		OriginData:     plan.Origins[0].IP.ValueString(),
+		OriginData:     primary.IP,
+		Wwwredir:       boolToInt(plan.Wwwredir.ValueBool()),
 	}
 
 	response, _, err := l7resource.Create(ctx, r.client, createOpts)
@@ -263,94 +560,68 @@ func (r *l7resourceResource) Create(ctx context.Context, req resource.CreateRequ
 		return
 	}
 
-	updateOpts, update := CheckingL7resourcePlanAttrIsNull(*plan, &response.Data.Result)
+	l7ResourceID := response.Data.Result.L7ResourceID
+	// The domain is created with default settings, the planned ones are applied
+	// with an update.
+	changes := l7resource.Diff(&response.Data.Result, expandL7ResourceModel(plan))
+	if !changes.IsEmpty() {
+		tflog.Debug(ctx, "Updating created l7 resource", map[string]any{"fields": changes.Fields()})
 
-	if update {
-		respUpd, _, err := l7resource.Update(ctx, r.client, updateOpts)
-		if err != nil {
+		if _, _, err := l7resource.Patch(ctx, r.client, changes.UpdateOpts(l7ResourceID)); err != nil {
 			resp.Diagnostics.AddError(
 				"Error Updating Servicepipe l7 resource",
-				"Could not update l7 resource, unexpected error: "+err.Error()+"ResID"+strconv.Itoa(int(plan.L7ResourceID.ValueInt64())),
+				fmt.Sprintf("Could not update l7 resource ID %d fields %v, unexpected error: %s", l7ResourceID, changes.Fields(), err),
 			)
 			return
 		}
-
-		results := hackSPSSLState(plan, respUpd)
-
-		// Convert from the API data model to the Terraform data model
-		plan = flatternL7ResourceModel(results.Data.Result)
 	}
 
-	var origins []*l7originResourceModel
-	for _, v := range planOrigins {
-		origin := expandL7OriginModel(v)
-
-		item, ok := CheckExistingOriginByIP(ctx, r.client, origin.IP, response.Data.Result.L7ResourceID)
-		if !ok {
-			// Generate API request body from plan
-			createOriginOpts := &l7origin.CreateOpts{
-				L7ResourceID: response.Data.Result.L7ResourceID,
-				IP:           origin.IP,
-				Weight:       origin.Weight,
-				Mode:         origin.Mode,
-			}
-
-			result, _, err := l7origin.Create(ctx, r.client, createOriginOpts)
-			if err != nil {
-				msg := fmt.Sprintf("%+v", result)
-				resp.Diagnostics.AddError(
-					"Error creating l7origin: "+msg,
-					"Could not create l7origin, unexpected error: "+err.Error(),
-				)
-				return
-			}
-			item = &result.Data.Result
-		}
-
-		originOpts := expandL7OriginModel(v)
-		originOpts.L7ResourceID = response.Data.Result.L7ResourceID
-		originOpts.ID = item.ID
-		updateOrig := false
-
-		if !v.IP.IsNull() || !v.IP.IsUnknown() {
-			originOpts.IP = v.IP.ValueString()
-			updateOrig = true
-		}
+	// The API creates the first origin from originData, reconcile its weight
+	// and mode together with the rest of planned origins.
+	originCache := newL7originCache(r.client)
+	currentOrigins, err := originCache.list(ctx, l7ResourceID)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Reading Servicepipe l7 origins",
+			"Could not list l7 origins of l7 resource ID "+strconv.Itoa(int(l7ResourceID))+": "+err.Error(),
+		)
+		return
+	}
 
-		if !v.Mode.IsNull() || !v.Mode.IsUnknown() {
-			originOpts.Mode = v.Mode.ValueString()
-			updateOrig = true
-		}
+	originOpts := originUpdateOpts{Strategy: originUpdateStrategyDefault, Parallelism: r.maxParallelRequests}
+	originsByIP, err := applyOriginChanges(ctx, r.client, l7ResourceID, currentOrigins, desiredOrigins, originOpts)
+	originCache.forget(l7ResourceID)
+	if err != nil {
+		appendErrorDiagnostics(&resp.Diagnostics, "Error creating l7origin", err)
+		return
+	}
 
-		if !v.Weight.IsNull() || !v.Weight.IsUnknown() {
-			originOpts.Weight = v.Weight.ValueInt64()
-			updateOrig = true
-		}
+	origins, err := readL7Origins(l7ResourceID, planOrigins, originsByIP, false)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Reading Servicepipe l7 origin",
+			err.Error(),
+		)
+		return
+	}
 
-		if updateOrig {
-			respUpd, _, err := l7origin.Update(ctx, r.client, originOpts)
-			if err != nil {
-				msg := fmt.Sprintf("%+v _ %+v", respUpd, originOpts)
-				resp.Diagnostics.AddError(
-					"Error Updating Servicepipe l7 origin: "+msg,
-					"Could not update l7 origin, unexpected error: "+err.Error(),
-				)
-				return
-			}
-		}
+	// The domain is read after its origins are changed, so modified_at
+	// includes the origin changes.
+	response, _, err = l7resource.GetByID(ctx, r.client, int(l7ResourceID))
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Reading Servicepipe l7 resource",
+			"Could not read Servicepipe l7 resource ID "+strconv.Itoa(int(l7ResourceID))+": "+err.Error(),
+		)
+		return
+	}
 
-		originResponse, _, err := l7origin.GetByID(ctx, r.client, int(response.Data.Result.L7ResourceID), int(item.ID))
-		if err != nil {
-			resp.Diagnostics.AddError(
-				"Error Reading Servicepipe l7 origin",
-				"Could not read Servicepipe l7 origin ID "+strconv.Itoa(int(item.ID))+": "+err.Error(),
-			)
-			return
-		}
+	results := hackSPSSLState(plan, response)
 
-		originResponse.Data.Result.L7ResourceID = response.Data.Result.L7ResourceID
-		origins = append(origins, flatternL7OriginModel(&originResponse.Data.Result))
-	}
+	// Convert from the API data model to the Terraform data model
+	settings := plan
+	plan = flatternL7ResourceModel(results.Data.Result)
+	copyL7ResourceLocalAttrs(plan, settings)
 	plan.Origins = origins
 	plan.LastUpdated = types.StringValue(time.Now().Format(time.RFC850))
 
@@ -363,6 +634,9 @@ func (r *l7resourceResource) Create(ctx context.Context, req resource.CreateRequ
 
 // Read refreshes the Terraform state with the latest data.
 func (r *l7resourceResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	ctx, span := startOperationSpan(ctx, "servicepipe_l7resource", "read")
+	defer func() { endOperationSpan(span, resp.Diagnostics) }()
+
 	// Get current state
 	var state *l7resourceResourceModel
 	diags := req.State.Get(ctx, &state)
@@ -381,23 +655,64 @@ func (r *l7resourceResource) Read(ctx context.Context, req resource.ReadRequest,
 		return
 	}
 
-	var origins []*l7originResourceModel
-	for _, v := range state.Origins {
-		originResponse, _, err := l7origin.GetByID(ctx, r.client, int(state.L7ResourceID.ValueInt64()), int(v.ID.ValueInt64()))
+	// All origins are read with a single list, origins of the state are matched
+	// by their IDs first, so IP changes made outside of Terraform show up.
+	l7ResourceID := state.L7ResourceID.ValueInt64()
+	originCache := newL7originCache(r.client)
+	originsByIP, err := originCache.byIP(ctx, l7ResourceID)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Reading Servicepipe l7 origins",
+			"Could not list l7 origins of l7 resource ID "+strconv.Itoa(int(l7ResourceID))+": "+err.Error(),
+		)
+		return
+	}
+
+	originsByID, err := originCache.byID(ctx, l7ResourceID)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Reading Servicepipe l7 origins",
+			"Could not list l7 origins of l7 resource ID "+strconv.Itoa(int(l7ResourceID))+": "+err.Error(),
+		)
+		return
+	}
+
+	// Imported resources have no origins in the state yet, all origins of the
+	// domain are adopted as IP origins.
+	if state.Origins == nil {
+		items, err := originCache.list(ctx, l7ResourceID)
 		if err != nil {
 			resp.Diagnostics.AddError(
-				"Error Reading Servicepipe l7 origin",
-				"Could not read Servicepipe l7 origin ID "+strconv.Itoa(int(v.ID.ValueInt64()))+": "+err.Error(),
+				"Error Reading Servicepipe l7 origins",
+				"Could not list l7 origins of l7 resource ID "+strconv.Itoa(int(l7ResourceID))+": "+err.Error(),
 			)
 			return
 		}
+		state.Origins = importL7OriginModels(items)
+	}
 
-		originResponse.Data.Result.L7ResourceID = state.L7ResourceID.ValueInt64()
-		origins = append(origins, flatternL7OriginModel(&originResponse.Data.Result))
+	for _, v := range state.Origins {
+		if !v.Hostname.IsNull() || v.ID.IsNull() || v.ID.IsUnknown() {
+			continue
+		}
+		if item, ok := originsByID[v.ID.ValueInt64()]; ok {
+			originsByIP[v.IP.ValueString()] = item
+		}
+	}
+
+	origins, err := readL7Origins(l7ResourceID, state.Origins, originsByIP, true)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Reading Servicepipe l7 origin",
+			err.Error(),
+		)
+		return
 	}
 
 	results := hackSPSSLState(state, resourceResponse)
+	settings := state
 	state = flatternL7ResourceModel(results.Data.Result)
+	copyL7ResourceLocalAttrs(state, settings)
 	state.Origins = origins
 
 	// Set refreshed state
@@ -408,8 +723,35 @@ func (r *l7resourceResource) Read(ctx context.Context, req resource.ReadRequest,
 	}
 }
 
+// ImportState imports a domain with all of its origins by its l7_resource_id.
+// Attributes that exist only in Terraform get their defaults.
+func (r *l7resourceResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	l7ResourceID, err := strconv.ParseInt(req.ID, 10, 64)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Invalid Import ID",
+			fmt.Sprintf("Expected the numeric l7_resource_id of the domain, got: %q", req.ID),
+		)
+		return
+	}
+
+	for name, value := range map[string]any{
+		"l7_resource_id":         l7ResourceID,
+		"origin_update_strategy": originUpdateStrategyDefault,
+		"origin_shift_steps":     int64(defaultOriginShiftSteps),
+		"origin_shift_pause":     int64(defaultOriginShiftPause),
+		"auto_weight":            false,
+		"deletion_protection":    false,
+	} {
+		resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root(name), value)...)
+	}
+}
+
 // Update updates the resource and sets the updated Terraform state on success.
 func (r *l7resourceResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	ctx, span := startOperationSpan(ctx, "servicepipe_l7resource", "update")
+	defer func() { endOperationSpan(span, resp.Diagnostics) }()
+
 	// Get current plan
 	var plan, state *l7resourceResourceModel
 	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
@@ -417,36 +759,82 @@ func (r *l7resourceResource) Update(ctx context.Context, req resource.UpdateRequ
 	if resp.Diagnostics.HasError() {
 		return
 	}
+	ctx = withAuditAddress(ctx, "servicepipe_l7resource", plan.L7ResourceName.ValueString())
+
+	// Only settings changed by the plan are applied to the domain, the update
+	// fails if the domain was modified since the state was refreshed.
+	changes := l7resource.Diff(expandL7ResourceModel(state), expandL7ResourceModel(plan))
+	if !changes.IsEmpty() {
+		tflog.Debug(ctx, "Updating l7 resource", map[string]any{"fields": changes.Fields()})
 
-	// Update existing order
-	item := expandL7ResourceModel(plan)
-	item.L7ResourceID = state.L7ResourceID.ValueInt64()
+		opts := changes.UpdateOpts(state.L7ResourceID.ValueInt64())
+		opts.IfModifiedAt = int(state.ModifiedAt.ValueInt64())
+		_, _, err := l7resource.Patch(ctx, r.client, opts)
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Error Updating Servicepipe l7 resource",
+				fmt.Sprintf("Could not update l7 resource ID %d fields %v, unexpected error: %s", state.L7ResourceID.ValueInt64(), changes.Fields(), err),
+			)
+			return
+		}
+	}
 
-	jsonOpts, err := json.Marshal(item)
+	planOrigins := plan.Origins
+	originUpdateOpts := newOriginUpdateOpts(plan, r.maxParallelRequests)
+
+	if err := resolveL7OriginModels(ctx, r.resolver, planOrigins); err != nil {
+		resp.Diagnostics.AddError(
+			"Error Resolving Origin Hostname",
+			err.Error(),
+		)
+		return
+	}
+
+	// Only origins managed by this resource are compared with the plan, origin
+	// IDs of hostnames are not kept in the state.
+	originCache := newL7originCache(r.client)
+	items, err := originCache.list(ctx, state.L7ResourceID.ValueInt64())
 	if err != nil {
 		resp.Diagnostics.AddError(
-			"Error Marshal req Servicepipe l7 resource",
-			"Could not Marshal l7 resource, unexpected error: "+err.Error(),
+			"Error Reading Servicepipe l7 origins",
+			"Could not list l7 origins of l7 resource ID "+strconv.Itoa(int(state.L7ResourceID.ValueInt64()))+": "+err.Error(),
 		)
 		return
 	}
 
-	opts, update := CheckPlanVsState(plan, state, item)
+	managedIPs := make(map[string]bool)
+	for _, v := range expandL7OriginModels(state.Origins) {
+		managedIPs[v.IP] = true
+	}
+
+	var currentOrigins []*l7origin.Item
+	for _, item := range items {
+		if managedIPs[item.IP] {
+			currentOrigins = append(currentOrigins, item)
+		}
+	}
+
+	desiredOrigins := expandL7OriginModels(planOrigins)
 
-	if !update {
+	originsByIP, err := applyOriginChanges(ctx, r.client, state.L7ResourceID.ValueInt64(), currentOrigins, desiredOrigins, originUpdateOpts)
+	originCache.forget(state.L7ResourceID.ValueInt64())
+	if err != nil {
+		appendErrorDiagnostics(&resp.Diagnostics, "Error Updating Servicepipe l7 origins", err)
 		return
 	}
 
-	_, _, err = l7resource.Update(ctx, r.client, opts)
+	origins, err := readL7Origins(state.L7ResourceID.ValueInt64(), planOrigins, originsByIP, false)
 	if err != nil {
 		resp.Diagnostics.AddError(
-			"Error Updating Servicepipe l7 resource",
-			"Could not update l7 resource, unexpected error: "+err.Error()+"ResID"+strconv.Itoa(int(plan.L7ResourceID.ValueInt64()))+string(jsonOpts),
+			"Error Reading Servicepipe l7 origin",
+			err.Error(),
 		)
 		return
 	}
 
-	// Get refreshed l7resource value from Servicepipe
+	// The domain is read after its origins are changed, so modified_at
+	// includes the origin changes and the next update isn't rejected as a
+	// concurrent modification.
 	response, _, err := l7resource.GetByID(ctx, r.client, int(state.L7ResourceID.ValueInt64()))
 	if err != nil {
 		resp.Diagnostics.AddError(
@@ -457,115 +845,9 @@ func (r *l7resourceResource) Update(ctx context.Context, req resource.UpdateRequ
 	}
 
 	results := hackSPSSLState(plan, response)
-
-	planOrigins := plan.Origins
+	settings := plan
 	plan = flatternL7ResourceModel(results.Data.Result)
-
-	for _, v := range planOrigins {
-		origin := expandL7OriginModel(v)
-		_, ok := CheckExistingOriginByIP(ctx, r.client, origin.IP, state.L7ResourceID.ValueInt64())
-		if !ok {
-			// Generate API request body from plan
-			createOriginOpts := &l7origin.CreateOpts{
-				L7ResourceID: state.L7ResourceID.ValueInt64(),
-				IP:           origin.IP,
-				Weight:       origin.Weight,
-				Mode:         origin.Mode,
-			}
-
-			result, _, err := l7origin.Create(ctx, r.client, createOriginOpts)
-			if err != nil {
-				msg := fmt.Sprintf("%+v", result)
-				resp.Diagnostics.AddError(
-					"Error creating l7origin: "+msg,
-					"Could not create l7origin, unexpected error: "+err.Error(),
-				)
-				return
-			}
-
-			result.Data.Result.L7ResourceID = state.L7ResourceID.ValueInt64()
-			state.Origins = append(state.Origins, flatternL7OriginModel(&result.Data.Result))
-		}
-	}
-
-	if len(state.Origins) != len(planOrigins) {
-		for i, v := range state.Origins {
-			_, ok := CheckPlanVsStateOrigin(planOrigins, v.IP.ValueString())
-			if !ok {
-				deleteOriginOpts := &l7origin.DeleteOpts{
-					ID:           v.ID.ValueInt64(),
-					L7ResourceID: state.L7ResourceID.ValueInt64(),
-				}
-
-				// Delete existing resource
-				result, _, err := l7origin.Delete(ctx, r.client, deleteOriginOpts)
-				if err != nil || result.Data.Result != "ok" {
-					resp.Diagnostics.AddError(
-						"Error Deleting l7origin",
-						"Could not delete l7origin, unexpected error: "+err.Error(),
-					)
-					return
-				}
-
-				state.Origins = removeL7originFromState(state.Origins, i)
-			}
-		}
-	}
-
-	for _, s := range state.Origins {
-		originOpts := expandL7OriginModel(s)
-		originOpts.L7ResourceID = state.L7ResourceID.ValueInt64()
-
-		updateOrig := false
-		for _, p := range planOrigins {
-			if p.IP == s.IP {
-				if !p.Mode.Equal(s.Mode) {
-					originOpts.Mode = p.Mode.ValueString()
-					updateOrig = true
-				}
-
-				if !p.Weight.Equal(s.Weight) {
-					originOpts.Weight = p.Weight.ValueInt64()
-					updateOrig = true
-				}
-
-				if !p.IP.Equal(s.IP) {
-					originOpts.IP = p.IP.ValueString()
-					updateOrig = true
-				}
-			}
-		}
-
-		if !updateOrig {
-			continue
-		}
-
-		_, _, err = l7origin.Update(ctx, r.client, originOpts)
-		if err != nil {
-			msg := fmt.Sprintf("%+v", originOpts)
-			resp.Diagnostics.AddError(
-				"Error Updating Servicepipe l7 origin: "+msg,
-				"Could not update l7 origin, unexpected error: "+err.Error(),
-			)
-			return
-		}
-	}
-
-	var origins []*l7originResourceModel
-	for _, v := range state.Origins {
-		originResponse, _, err := l7origin.GetByID(ctx, r.client, int(state.L7ResourceID.ValueInt64()), int(v.ID.ValueInt64()))
-		if err != nil {
-			resp.Diagnostics.AddError(
-				"Error Reading Servicepipe l7 origin",
-				"Could not read Servicepipe l7 origin ID "+strconv.Itoa(int(v.ID.ValueInt64()))+": "+err.Error(),
-			)
-			return
-		}
-
-		originResponse.Data.Result.L7ResourceID = state.L7ResourceID.ValueInt64()
-		origins = append(origins, flatternL7OriginModel(&originResponse.Data.Result))
-	}
-
+	copyL7ResourceLocalAttrs(plan, settings)
 	plan.Origins = origins
 	plan.LastUpdated = types.StringValue(time.Now().Format(time.RFC850))
 
@@ -578,77 +860,111 @@ func (r *l7resourceResource) Update(ctx context.Context, req resource.UpdateRequ
 
 // Delete deletes the resource and removes the Terraform state on success.
 func (r *l7resourceResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	ctx, span := startOperationSpan(ctx, "servicepipe_l7resource", "delete")
+	defer func() { endOperationSpan(span, resp.Diagnostics) }()
+
 	var state l7resourceResourceModel
 	diags := req.State.Get(ctx, &state)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
+	ctx = withAuditAddress(ctx, "servicepipe_l7resource", state.L7ResourceName.ValueString())
+
+	if state.DeletionProtection.ValueBool() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("deletion_protection"),
+			"Deletion Protection Enabled",
+			fmt.Sprintf("Cannot destroy l7 resource %q while deletion_protection is enabled. "+
+				"Set deletion_protection to false and apply the change first, then destroy the resource.", state.L7ResourceName.ValueString()),
+		)
+		return
+	}
 
 	deleteOriginOpts := &l7resource.DeleteOpts{
 		L7ResourceID: int(state.L7ResourceID.ValueInt64()),
 	}
 
 	result, _, err := l7resource.Delete(ctx, r.client, deleteOriginOpts)
-	if err != nil || result.Data.Result != "ok" {
+	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error Deleting l7resource",
 			"Could not delete l7resource, unexpected error: "+err.Error(),
 		)
 		return
 	}
+	if result.Data.Result != "ok" {
+		resp.Diagnostics.AddError(
+			"Error Deleting l7resource",
+			fmt.Sprintf("Could not delete l7resource, got %q result", result.Data.Result),
+		)
+		return
+	}
 }
 
 func expandL7ResourceModel(model *l7resourceResourceModel) *l7resource.Item {
 	return &l7resource.Item{
 		L7ResourceID:          model.L7ResourceID.ValueInt64(),
-		L7ResourceName:        model.L7ResourceName.ValueString(),
-		L7ResourceIsActive:    int(model.L7ResourceIsActive.ValueInt64()),
-		L7ProtectionDisable:   int(model.L7ProtectionDisable.ValueInt64()),
-		UseCustomSsl:          int(model.UseCustomSsl.ValueInt64()),
-		UseLetsencryptSsl:     int(model.UseLetsencryptSsl.ValueInt64()),
+		L7ResourceName:        expandDomainName(model.L7ResourceName),
+		L7ResourceIsActive:    boolToInt(model.L7ResourceIsActive.ValueBool()),
+		L7ProtectionDisable:   boolToInt(model.L7ProtectionDisable.ValueBool()),
+		UseCustomSsl:          boolToInt(model.UseCustomSsl.ValueBool()),
+		UseLetsencryptSsl:     boolToInt(model.UseLetsencryptSsl.ValueBool()),
 		CustomSslKey:          model.CustomSslKey.ValueString(),
 		CustomSslCrt:          model.CustomSslCrt.ValueString(),
-		Forcessl:              int(model.Forcessl.ValueInt64()),
-		ServiceHTTP2:          int(model.ServiceHTTP2.ValueInt64()),
-		GeoipMode:             int(model.GeoipMode.ValueInt64()),
-		GeoipList:             model.GeoipList.ValueString(),
-		GlobalWhitelistActive: int(model.GlobalWhitelistActive.ValueInt64()),
-		HTTP2https:            int(model.HTTP2https.ValueInt64()),
-		HTTPS2http:            int(model.HTTPS2http.ValueInt64()),
+		Forcessl:              boolToInt(model.Forcessl.ValueBool()),
+		ServiceHTTP2:          boolToInt(model.ServiceHTTP2.ValueBool()),
+		GeoipMode:             expandGeoipMode(model.GeoipMode),
+		GeoipList:             expandGeoipList(model.GeoipList),
+		GlobalWhitelistActive: boolToInt(model.GlobalWhitelistActive.ValueBool()),
+		HTTP2https:            boolToInt(model.HTTP2https.ValueBool()),
+		HTTPS2http:            boolToInt(model.HTTPS2http.ValueBool()),
 		ProtectedIp:           model.ProtectedIp.ValueString(),
-		Wwwredir:              int(model.Wwwredir.ValueInt64()),
-		Cdn:                   int(model.Cdn.ValueInt64()),
+		Wwwredir:              boolToInt(model.Wwwredir.ValueBool()),
+		Cdn:                   boolToInt(model.Cdn.ValueBool()),
 		CdnHost:               model.CdnHost.ValueString(),
 		CdnProxyHost:          model.CdnProxyHost.ValueString(),
 	}
 }
 
 func flatternL7ResourceModel(item l7resource.Item) *l7resourceResourceModel {
+	nameASCII, nameUnicode := flattenDomainName(types.StringValue(item.L7ResourceName))
+
 	return &l7resourceResourceModel{
 		L7ResourceID:          types.Int64Value(item.L7ResourceID),
 		L7ResourceName:        types.StringValue(item.L7ResourceName),
-		L7ResourceIsActive:    types.Int64Value(int64(item.L7ResourceIsActive)),
-		L7ProtectionDisable:   types.Int64Value(int64(item.L7ProtectionDisable)),
-		UseCustomSsl:          types.Int64Value(int64(item.UseCustomSsl)),
-		UseLetsencryptSsl:     types.Int64Value(int64(item.UseLetsencryptSsl)),
+		L7ResourceNameASCII:   nameASCII,
+		L7ResourceNameUnicode: nameUnicode,
+		L7ResourceIsActive:    types.BoolValue(item.L7ResourceIsActive != 0),
+		L7ProtectionDisable:   types.BoolValue(item.L7ProtectionDisable != 0),
+		UseCustomSsl:          types.BoolValue(item.UseCustomSsl != 0),
+		UseLetsencryptSsl:     types.BoolValue(item.UseLetsencryptSsl != 0),
 		CustomSslKey:          types.StringValue(item.CustomSslKey),
 		CustomSslCrt:          types.StringValue(item.CustomSslCrt),
-		Forcessl:              types.Int64Value(int64(item.Forcessl)),
-		ServiceHTTP2:          types.Int64Value(int64(item.ServiceHTTP2)),
-		GeoipMode:             types.Int64Value(int64(item.GeoipMode)),
-		GeoipList:             types.StringValue(item.GeoipList),
-		GlobalWhitelistActive: types.Int64Value(int64(item.GlobalWhitelistActive)),
-		HTTP2https:            types.Int64Value(int64(item.HTTP2https)),
-		HTTPS2http:            types.Int64Value(int64(item.HTTPS2http)),
+		Forcessl:              types.BoolValue(item.Forcessl != 0),
+		ServiceHTTP2:          types.BoolValue(item.ServiceHTTP2 != 0),
+		GeoipMode:             flattenGeoipMode(item.GeoipMode),
+		GeoipList:             flattenGeoipList(item.GeoipList),
+		GlobalWhitelistActive: types.BoolValue(item.GlobalWhitelistActive != 0),
+		HTTP2https:            types.BoolValue(item.HTTP2https != 0),
+		HTTPS2http:            types.BoolValue(item.HTTPS2http != 0),
 		ProtectedIp:           types.StringValue(item.ProtectedIp),
-		Wwwredir:              types.Int64Value(int64(item.Wwwredir)),
-		Cdn:                   types.Int64Value(int64(item.Cdn)),
+		Wwwredir:              types.BoolValue(item.Wwwredir != 0),
+		Cdn:                   types.BoolValue(item.Cdn != 0),
 		CdnHost:               types.StringValue(item.CdnHost),
 		CdnProxyHost:          types.StringValue(item.CdnProxyHost),
+		ModifiedAt:            types.Int64Value(int64(item.ModifiedAt)),
 	}
 }
 
+func boolToInt(value bool) int {
+	if value {
+		return 1
+	}
+
+	return 0
+}
+
 func expandL7OriginModel(item *l7originResourceModel) *l7origin.Item {
 	return &l7origin.Item{
 		L7ResourceID: item.L7ResourceID.ValueInt64(),
@@ -667,255 +983,253 @@ func flatternL7OriginModel(item *l7origin.Item) *l7originResourceModel {
 		ID:           types.Int64Value(item.ID),
 		IP:           types.StringValue(item.IP),
 		Weight:       types.Int64Value(item.Weight),
+		WeightRatio:  types.Int64Null(),
 		Mode:         types.StringValue(item.Mode),
+		Hostname:     types.StringNull(),
+		ResolvedIPs:  types.ListNull(types.StringType),
 		CreatedAt:    types.Int64Value(item.CreatedAt),
 		ModifiedAt:   types.Int64Value(item.ModifiedAt),
 	}
 }
 
-func CheckExistingOriginByIP(ctx context.Context, client *v1.Client, ip string, resourceID int64) (*l7origin.Item, bool) {
-	listOpts := &l7origin.ListOpts{
-		L7ResourceID: resourceID,
-	}
-
-	origins, _, err := l7origin.List(ctx, client, listOpts)
-	if err != nil {
-		log.Fatal(err)
-	}
+// importL7OriginModels returns models of all origins of an imported domain
+// ordered by their IDs.
+func importL7OriginModels(items []*l7origin.Item) []*l7originResourceModel {
+	sorted := make([]*l7origin.Item, len(items))
+	copy(sorted, items)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].ID < sorted[j].ID
+	})
 
-	for _, item := range origins {
-		if item.IP == ip {
-			return item, true
-		}
+	models := make([]*l7originResourceModel, 0, len(sorted))
+	for _, item := range sorted {
+		models = append(models, flatternL7OriginModel(item))
 	}
 
-	return &l7origin.Item{}, false
+	return models
 }
 
-func CheckPlanVsStateOrigin(origins []*l7originResourceModel, ip string) (*l7origin.Item, bool) {
-	for _, item := range origins {
-		if item.IP.ValueString() == ip {
-			return expandL7OriginModel(item), true
-		}
+// flatternL7OriginHostnameModel builds the model of a hostname origin from the
+// origins of its resolved IPs.
+func flatternL7OriginHostnameModel(model *l7originResourceModel, items []*l7origin.Item, l7ResourceID int64) *l7originResourceModel {
+	ips := make([]string, 0, len(items))
+	for _, item := range items {
+		ips = append(ips, item.IP)
 	}
 
-	return &l7origin.Item{}, false
-}
-
-func removeL7originFromState(slice []*l7originResourceModel, s int) []*l7originResourceModel {
-	return append(slice[:s], slice[s+1:]...)
-}
-
-func hackSPSSLState(plan *l7resourceResourceModel, l7res *l7resource.Data) *l7resource.Data {
-	// Hack - servicepipe api doesn't support ssl cert params in response
-	if !plan.CustomSslKey.IsNull() || !plan.CustomSslKey.IsUnknown() {
-		l7res.Data.Result.CustomSslKey = plan.CustomSslKey.ValueString()
+	result := &l7originResourceModel{
+		L7ResourceID: types.Int64Value(l7ResourceID),
+		ID:           types.Int64Null(),
+		Weight:       model.Weight,
+		Mode:         model.Mode,
+		IP:           types.StringNull(),
+		Hostname:     model.Hostname,
+		ResolvedIPs:  resolvedIPsValue(ips),
+		CreatedAt:    types.Int64Null(),
+		ModifiedAt:   types.Int64Null(),
 	}
 
-	if !plan.CustomSslCrt.IsNull() || !plan.CustomSslCrt.IsUnknown() {
-		l7res.Data.Result.CustomSslCrt = plan.CustomSslCrt.ValueString()
+	// Origins of the hostname share the weight and mode, the first one shows
+	// changes made outside of Terraform.
+	if len(items) > 0 {
+		result.Weight = types.Int64Value(items[0].Weight)
+		result.Mode = types.StringValue(items[0].Mode)
 	}
 
-	return l7res
+	return result
 }
 
-func CheckPlanVsState(plan *l7resourceResourceModel, state *l7resourceResourceModel, item *l7resource.Item) (*l7resource.Item, bool) {
-	update := false
+// expandL7OriginModels returns one origin item per origin IP, hostnames are
+// expanded into their resolved IPs with the shared weight and mode.
+func expandL7OriginModels(models []*l7originResourceModel) []*l7origin.Item {
+	items := make([]*l7origin.Item, 0, len(models))
+	for _, model := range models {
+		if model.Hostname.IsNull() {
+			items = append(items, expandL7OriginModel(model))
+			continue
+		}
 
-	if !plan.L7ResourceName.Equal(state.L7ResourceName) {
-		item.L7ResourceName = plan.L7ResourceName.ValueString()
-		update = true
+		for _, ip := range resolvedIPs(model.ResolvedIPs) {
+			items = append(items, &l7origin.Item{
+				L7ResourceID: model.L7ResourceID.ValueInt64(),
+				Weight:       model.Weight.ValueInt64(),
+				Mode:         model.Mode.ValueString(),
+				IP:           ip,
+			})
+		}
 	}
 
-	if !plan.L7ResourceIsActive.Equal(state.L7ResourceIsActive) {
-		item.L7ResourceIsActive = int(plan.L7ResourceIsActive.ValueInt64())
-		update = true
-	}
+	return items
+}
 
-	if !plan.L7ProtectionDisable.Equal(state.L7ProtectionDisable) {
-		item.L7ProtectionDisable = int(plan.L7ProtectionDisable.ValueInt64())
-		update = true
-	}
+// resolveL7OriginModels resolves hostnames which were unknown at plan time.
+func resolveL7OriginModels(ctx context.Context, resolver hostResolver, models []*l7originResourceModel) error {
+	for _, model := range models {
+		if model.Hostname.IsNull() {
+			model.ResolvedIPs = types.ListNull(types.StringType)
+			continue
+		}
 
-	if !plan.UseCustomSsl.Equal(state.UseCustomSsl) {
-		item.UseCustomSsl = int(plan.UseCustomSsl.ValueInt64())
-		update = true
-	}
+		if !model.ResolvedIPs.IsUnknown() && !model.ResolvedIPs.IsNull() {
+			continue
+		}
 
-	if !plan.UseLetsencryptSsl.Equal(state.UseLetsencryptSsl) {
-		item.UseLetsencryptSsl = int(plan.UseLetsencryptSsl.ValueInt64())
-		update = true
+		ips, err := resolveHostname(ctx, resolver, model.Hostname.ValueString())
+		if err != nil {
+			return err
+		}
+		model.ResolvedIPs = resolvedIPsValue(ips)
 	}
 
-	if !plan.CustomSslKey.Equal(state.CustomSslKey) {
-		item.CustomSslKey = plan.CustomSslKey.ValueString()
-		update = true
-	}
+	return nil
+}
 
-	if !plan.CustomSslCrt.Equal(state.CustomSslCrt) {
-		item.CustomSslCrt = plan.CustomSslCrt.ValueString()
-		update = true
-	}
-	if !plan.Forcessl.Equal(state.Forcessl) {
-		item.Forcessl = int(plan.Forcessl.ValueInt64())
-		update = true
+// l7OriginResolvedIPsEqual reports whether the hostname origin resolves to the
+// same IPs as in the state.
+func l7OriginResolvedIPsEqual(stateOrigins []*l7originResourceModel, origin *l7originResourceModel) bool {
+	if origin.Hostname.IsNull() {
+		return true
 	}
 
-	if !plan.ServiceHTTP2.Equal(state.ServiceHTTP2) {
-		item.ServiceHTTP2 = int(plan.ServiceHTTP2.ValueInt64())
-		update = true
+	for _, v := range stateOrigins {
+		if v.Hostname.Equal(origin.Hostname) {
+			return v.ResolvedIPs.Equal(origin.ResolvedIPs)
+		}
 	}
 
-	if !plan.GeoipMode.Equal(state.GeoipMode) {
-		item.GeoipMode = int(plan.GeoipMode.ValueInt64())
-		update = true
-	}
+	return false
+}
 
-	if !plan.GeoipList.Equal(state.GeoipList) {
-		item.GeoipList = plan.GeoipList.ValueString()
-		update = true
+// l7OriginWeightsEqual reports whether planned origins keep weights from the state.
+func l7OriginWeightsEqual(stateOrigins, planOrigins []*l7originResourceModel) bool {
+	if len(stateOrigins) != len(planOrigins) {
+		return false
 	}
 
-	if !plan.GlobalWhitelistActive.Equal(state.GlobalWhitelistActive) {
-		item.GlobalWhitelistActive = int(plan.GlobalWhitelistActive.ValueInt64())
-		update = true
+	for i, v := range planOrigins {
+		if !v.Weight.Equal(stateOrigins[i].Weight) {
+			return false
+		}
 	}
 
-	if !plan.HTTP2https.Equal(state.HTTP2https) {
-		item.HTTP2https = int(plan.HTTP2https.ValueInt64())
-		update = true
-	}
+	return true
+}
 
-	if !plan.HTTPS2http.Equal(state.HTTPS2http) {
-		item.HTTPS2http = int(plan.HTTPS2http.ValueInt64())
-		update = true
+// validateL7OriginIPsUnique checks that resolved hostnames don't overlap with
+// other origins.
+func validateL7OriginIPsUnique(items []*l7origin.Item) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	seen := make(map[string]bool, len(items))
+	for _, item := range items {
+		if seen[item.IP] {
+			diags.AddAttributeError(
+				path.Root("origins"),
+				"Duplicate Origin IP",
+				fmt.Sprintf("Origin IP %q is used by more than one origin, check the IPs origin hostnames are resolved to.", item.IP),
+			)
+			continue
+		}
+		seen[item.IP] = true
 	}
 
-	if !plan.ProtectedIp.Equal(state.ProtectedIp) {
-		item.ProtectedIp = plan.ProtectedIp.ValueString()
-		update = true
-	}
+	return diags
+}
 
-	if !plan.Wwwredir.Equal(state.Wwwredir) {
-		item.Wwwredir = int(plan.Wwwredir.ValueInt64())
-		update = true
+// primaryL7Origin returns the first primary origin, the API serves the domain
+// from it right after creation. It falls back to the first origin.
+func primaryL7Origin(origins []*l7origin.Item) *l7origin.Item {
+	for _, origin := range origins {
+		if origin.Mode == l7origin.ModePrimary {
+			return origin
+		}
 	}
 
-	if !plan.Cdn.Equal(state.Cdn) {
-		item.Cdn = int(plan.Cdn.ValueInt64())
-		update = true
+	return origins[0]
+}
+
+// listL7Origins returns origins of every page of the l7 resource origin list.
+func listL7Origins(ctx context.Context, client *v1.Client, l7ResourceID int64) ([]*l7origin.Item, error) {
+	listOpts := &l7origin.ListOpts{
+		L7ResourceID: l7ResourceID,
 	}
 
-	if !plan.CdnHost.Equal(state.CdnHost) {
-		item.CdnHost = plan.CdnHost.ValueString()
-		update = true
+	origins, _, err := l7origin.ListAll(ctx, client, listOpts)
+	if err != nil {
+		return nil, err
 	}
 
-	if !plan.CdnProxyHost.Equal(state.CdnProxyHost) {
-		item.CdnProxyHost = plan.CdnProxyHost.ValueString()
-		update = true
+	for _, item := range origins {
+		item.L7ResourceID = l7ResourceID
 	}
 
-	return item, update
+	return origins, nil
 }
 
-func CheckingL7resourcePlanAttrIsNull(plan l7resourceResourceModel, item *l7resource.Item) (*l7resource.Item, bool) {
-	update := false
+// readL7Origins returns origins of the models in their order from originsByIP,
+// which has to hold origins as the API returned them. When refresh is set, IPs
+// of hostnames that have no origin anymore are dropped from the result instead
+// of failing.
+func readL7Origins(l7ResourceID int64, models []*l7originResourceModel, originsByIP map[string]*l7origin.Item, refresh bool) ([]*l7originResourceModel, error) {
+	origins := make([]*l7originResourceModel, 0, len(models))
+	for _, v := range models {
+		if !v.Hostname.IsNull() {
+			var items []*l7origin.Item
+			for _, ip := range resolvedIPs(v.ResolvedIPs) {
+				item, ok := originsByIP[ip]
+				if !ok {
+					if refresh {
+						continue
+					}
+					return nil, fmt.Errorf("could not find Servicepipe l7 origin %s of %s", ip, v.Hostname.ValueString())
+				}
+				items = append(items, item)
+			}
 
-	if !plan.L7ResourceName.IsNull() || !plan.L7ResourceName.IsUnknown() {
-		item.L7ResourceName = plan.L7ResourceName.ValueString()
-		update = true
-	}
+			origin := flatternL7OriginHostnameModel(v, items, l7ResourceID)
+			origin.WeightRatio = v.WeightRatio
+			origins = append(origins, origin)
+			continue
+		}
 
-	if !plan.L7ResourceIsActive.IsNull() || !plan.L7ResourceIsActive.IsUnknown() {
-		item.L7ResourceIsActive = int(plan.L7ResourceIsActive.ValueInt64())
-		update = true
-	}
+		item, ok := originsByIP[v.IP.ValueString()]
+		if !ok {
+			return nil, fmt.Errorf("could not find Servicepipe l7 origin %s", v.IP.ValueString())
+		}
 
-	if !plan.L7ProtectionDisable.IsNull() || !plan.L7ProtectionDisable.IsUnknown() {
-		item.L7ProtectionDisable = int(plan.L7ProtectionDisable.ValueInt64())
-		update = true
+		item.L7ResourceID = l7ResourceID
+		origin := flatternL7OriginModel(item)
+		origin.WeightRatio = v.WeightRatio
+		origins = append(origins, origin)
 	}
 
-	if !plan.UseCustomSsl.IsNull() || !plan.UseCustomSsl.IsUnknown() {
-		item.UseCustomSsl = int(plan.UseCustomSsl.ValueInt64())
-		update = true
-	}
+	return origins, nil
+}
 
-	if !plan.UseLetsencryptSsl.IsNull() || !plan.UseLetsencryptSsl.IsUnknown() {
-		item.UseLetsencryptSsl = int(plan.UseLetsencryptSsl.ValueInt64())
-		update = true
-	}
+// copyL7ResourceLocalAttrs copies attributes that exist only in Terraform and
+// are not returned by the API. The configured form of the domain name is kept
+// while it is equal to the one the API returns.
+func copyL7ResourceLocalAttrs(to, from *l7resourceResourceModel) {
+	if domainNamesEqual(to.L7ResourceName, from.L7ResourceName) {
+		to.L7ResourceName = from.L7ResourceName
+	}
+	to.OriginUpdateStrategy = from.OriginUpdateStrategy
+	to.OriginShiftSteps = from.OriginShiftSteps
+	to.OriginShiftPause = from.OriginShiftPause
+	to.AutoWeight = from.AutoWeight
+	to.DeletionProtection = from.DeletionProtection
+	to.LastUpdated = from.LastUpdated
+}
 
+func hackSPSSLState(plan *l7resourceResourceModel, l7res *l7resource.Data) *l7resource.Data {
+	// Hack - servicepipe api doesn't support ssl cert params in response
 	if !plan.CustomSslKey.IsNull() || !plan.CustomSslKey.IsUnknown() {
-		item.CustomSslKey = plan.CustomSslKey.ValueString()
-		update = true
+		l7res.Data.Result.CustomSslKey = plan.CustomSslKey.ValueString()
 	}
 
 	if !plan.CustomSslCrt.IsNull() || !plan.CustomSslCrt.IsUnknown() {
-		item.CustomSslCrt = plan.CustomSslCrt.ValueString()
-		update = true
-	}
-
-	if !plan.Forcessl.IsNull() || !plan.Forcessl.IsUnknown() {
-		item.Forcessl = int(plan.Forcessl.ValueInt64())
-		update = true
-	}
-
-	if !plan.ServiceHTTP2.IsNull() || !plan.ServiceHTTP2.IsUnknown() {
-		item.ServiceHTTP2 = int(plan.ServiceHTTP2.ValueInt64())
-		update = true
-	}
-
-	if !plan.GeoipMode.IsNull() || !plan.GeoipMode.IsUnknown() {
-		item.GeoipMode = int(plan.GeoipMode.ValueInt64())
-		update = true
-	}
-
-	if !plan.GeoipList.IsNull() || !plan.GeoipList.IsUnknown() {
-		item.GeoipList = plan.GeoipList.ValueString()
-		update = true
-	}
-
-	if !plan.GlobalWhitelistActive.IsNull() || !plan.GlobalWhitelistActive.IsUnknown() {
-		item.GlobalWhitelistActive = int(plan.GlobalWhitelistActive.ValueInt64())
-		update = true
-	}
-
-	if !plan.HTTP2https.IsNull() || !plan.HTTP2https.IsUnknown() {
-		item.HTTP2https = int(plan.HTTP2https.ValueInt64())
-		update = true
-	}
-
-	if !plan.HTTPS2http.IsNull() || !plan.HTTPS2http.IsUnknown() {
-		item.HTTPS2http = int(plan.HTTPS2http.ValueInt64())
-		update = true
-	}
-
-	if !plan.ProtectedIp.IsNull() || !plan.ProtectedIp.IsUnknown() {
-		item.ProtectedIp = plan.ProtectedIp.ValueString()
-		update = true
-	}
-
-	if !plan.Wwwredir.IsNull() || !plan.Wwwredir.IsUnknown() {
-		item.Wwwredir = int(plan.Wwwredir.ValueInt64())
-		update = true
-	}
-
-	if !plan.Cdn.IsNull() || !plan.Cdn.IsUnknown() {
-		item.Cdn = int(plan.Cdn.ValueInt64())
-		update = true
-	}
-
-	if !plan.CdnHost.IsNull() || !plan.CdnHost.IsUnknown() {
-		item.CdnHost = plan.CdnHost.ValueString()
-		update = true
-	}
-
-	if !plan.CdnProxyHost.IsNull() || !plan.CdnProxyHost.IsUnknown() {
-		item.CdnProxyHost = plan.CdnProxyHost.ValueString()
-		update = true
+		l7res.Data.Result.CustomSslCrt = plan.CustomSslCrt.ValueString()
 	}
 
-	return item, update
+	return l7res
 }
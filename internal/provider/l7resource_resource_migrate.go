@@ -0,0 +1,184 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// l7resourceResourceModelV0 maps the version 0 schema data, toggles were 0/1
+// integers mirroring the API flags.
+type l7resourceResourceModelV0 struct {
+	L7ResourceID          types.Int64  `tfsdk:"l7_resource_id"`
+	L7ResourceName        types.String `tfsdk:"l7_resource_name"`
+	L7ResourceIsActive    types.Int64  `tfsdk:"l7_resource_is_active"`
+	L7ProtectionDisable   types.Int64  `tfsdk:"l7_protection_disable"`
+	UseCustomSsl          types.Int64  `tfsdk:"use_custom_ssl"`
+	UseLetsencryptSsl     types.Int64  `tfsdk:"use_letsencrypt_ssl"`
+	CustomSslKey          types.String `tfsdk:"custom_ssl_key"`
+	CustomSslCrt          types.String `tfsdk:"custom_ssl_crt"`
+	Forcessl              types.Int64  `tfsdk:"force_ssl"`
+	ServiceHTTP2          types.Int64  `tfsdk:"service_http2"`
+	GeoipMode             types.Int64  `tfsdk:"geoip_mode"`
+	GeoipList             types.String `tfsdk:"geoip_list"`
+	GlobalWhitelistActive types.Int64  `tfsdk:"global_whitelist_active"`
+	HTTP2https            types.Int64  `tfsdk:"http_2_https"`
+	HTTPS2http            types.Int64  `tfsdk:"https_2_http"`
+	ProtectedIp           types.String `tfsdk:"protected_ip"`
+	Wwwredir              types.Int64  `tfsdk:"www_redir"`
+	Cdn                   types.Int64  `tfsdk:"cdn"`
+	CdnHost               types.String `tfsdk:"cdn_host"`
+	CdnProxyHost          types.String `tfsdk:"cdn_proxy_host"`
+
+	Origins []*l7originResourceModelV0 `tfsdk:"origins"`
+
+	LastUpdated types.String `tfsdk:"last_updated"`
+}
+
+// l7originResourceModelV0 maps origins of the version 0 schema, they had
+// neither hostnames nor weight ratios.
+type l7originResourceModelV0 struct {
+	L7ResourceID types.Int64  `tfsdk:"l7_resource_id"`
+	ID           types.Int64  `tfsdk:"id"`
+	Weight       types.Int64  `tfsdk:"weight"`
+	Mode         types.String `tfsdk:"mode"`
+	IP           types.String `tfsdk:"ip"`
+	CreatedAt    types.Int64  `tfsdk:"created_at"`
+	ModifiedAt   types.Int64  `tfsdk:"modified_at"`
+}
+
+// UpgradeState upgrades states of prior schema versions.
+func (r *l7resourceResource) UpgradeState(_ context.Context) map[int64]resource.StateUpgrader {
+	schemaV0 := l7resourceSchemaV0()
+
+	return map[int64]resource.StateUpgrader{
+		0: {
+			PriorSchema:   &schemaV0,
+			StateUpgrader: upgradeL7resourceStateV0,
+		},
+	}
+}
+
+// upgradeL7resourceStateV0 converts 0/1 toggles into booleans and the GeoIP
+// settings into the structured policy.
+func upgradeL7resourceStateV0(ctx context.Context, req resource.UpgradeStateRequest, resp *resource.UpgradeStateResponse) {
+	var prior l7resourceResourceModelV0
+	resp.Diagnostics.Append(req.State.Get(ctx, &prior)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	nameASCII, nameUnicode := flattenDomainName(prior.L7ResourceName)
+
+	// Attributes added after version 0 get their schema defaults, so upgraded
+	// states don't plan an update to the defaults.
+	upgraded := l7resourceResourceModel{
+		L7ResourceID:          prior.L7ResourceID,
+		L7ResourceName:        prior.L7ResourceName,
+		L7ResourceNameASCII:   nameASCII,
+		L7ResourceNameUnicode: nameUnicode,
+		L7ResourceIsActive:    int64ToBool(prior.L7ResourceIsActive),
+		L7ProtectionDisable:   int64ToBool(prior.L7ProtectionDisable),
+		UseCustomSsl:          int64ToBool(prior.UseCustomSsl),
+		UseLetsencryptSsl:     int64ToBool(prior.UseLetsencryptSsl),
+		CustomSslKey:          prior.CustomSslKey,
+		CustomSslCrt:          prior.CustomSslCrt,
+		Forcessl:              int64ToBool(prior.Forcessl),
+		ServiceHTTP2:          int64ToBool(prior.ServiceHTTP2),
+		GeoipMode:             flattenGeoipMode(int(prior.GeoipMode.ValueInt64())),
+		GeoipList:             flattenGeoipList(prior.GeoipList.ValueString()),
+		GlobalWhitelistActive: int64ToBool(prior.GlobalWhitelistActive),
+		HTTP2https:            int64ToBool(prior.HTTP2https),
+		HTTPS2http:            int64ToBool(prior.HTTPS2http),
+		ProtectedIp:           prior.ProtectedIp,
+		Wwwredir:              int64ToBool(prior.Wwwredir),
+		Cdn:                   int64ToBool(prior.Cdn),
+		CdnHost:               prior.CdnHost,
+		CdnProxyHost:          prior.CdnProxyHost,
+		Origins:               upgradeL7originModelsV0(prior.Origins),
+		OriginUpdateStrategy:  types.StringValue(originUpdateStrategyDefault),
+		OriginShiftSteps:      types.Int64Value(defaultOriginShiftSteps),
+		OriginShiftPause:      types.Int64Value(defaultOriginShiftPause),
+		AutoWeight:            types.BoolValue(false),
+		DeletionProtection:    types.BoolValue(false),
+		LastUpdated:           prior.LastUpdated,
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, upgraded)...)
+}
+
+// upgradeL7originModelsV0 converts version 0 origins, they are set by IP
+// addresses.
+func upgradeL7originModelsV0(prior []*l7originResourceModelV0) []*l7originResourceModel {
+	origins := make([]*l7originResourceModel, 0, len(prior))
+	for _, origin := range prior {
+		origins = append(origins, &l7originResourceModel{
+			L7ResourceID: origin.L7ResourceID,
+			ID:           origin.ID,
+			Weight:       origin.Weight,
+			WeightRatio:  types.Int64Null(),
+			Mode:         origin.Mode,
+			IP:           origin.IP,
+			Hostname:     types.StringNull(),
+			ResolvedIPs:  types.ListNull(types.StringType),
+			CreatedAt:    origin.CreatedAt,
+			ModifiedAt:   origin.ModifiedAt,
+		})
+	}
+
+	return origins
+}
+
+func int64ToBool(value types.Int64) types.Bool {
+	if value.IsNull() || value.IsUnknown() {
+		return types.BoolNull()
+	}
+
+	return types.BoolValue(value.ValueInt64() != 0)
+}
+
+// l7resourceSchemaV0 returns the version 0 schema released before schema
+// versioning, it is only used to read prior states.
+func l7resourceSchemaV0() schema.Schema {
+	return schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"l7_resource_id":          schema.Int64Attribute{Computed: true},
+			"l7_resource_name":        schema.StringAttribute{Required: true},
+			"l7_resource_is_active":   schema.Int64Attribute{Optional: true, Computed: true},
+			"l7_protection_disable":   schema.Int64Attribute{Optional: true, Computed: true},
+			"use_custom_ssl":          schema.Int64Attribute{Optional: true, Computed: true},
+			"use_letsencrypt_ssl":     schema.Int64Attribute{Optional: true, Computed: true},
+			"custom_ssl_key":          schema.StringAttribute{Optional: true, Computed: true},
+			"custom_ssl_crt":          schema.StringAttribute{Optional: true, Computed: true},
+			"force_ssl":               schema.Int64Attribute{Optional: true, Computed: true},
+			"service_http2":           schema.Int64Attribute{Optional: true, Computed: true},
+			"geoip_mode":              schema.Int64Attribute{Optional: true, Computed: true},
+			"geoip_list":              schema.StringAttribute{Optional: true, Computed: true},
+			"global_whitelist_active": schema.Int64Attribute{Optional: true, Computed: true},
+			"http_2_https":            schema.Int64Attribute{Optional: true, Computed: true},
+			"https_2_http":            schema.Int64Attribute{Optional: true, Computed: true},
+			"protected_ip":            schema.StringAttribute{Computed: true},
+			"www_redir":               schema.Int64Attribute{Optional: true, Computed: true},
+			"cdn":                     schema.Int64Attribute{Optional: true, Computed: true},
+			"cdn_host":                schema.StringAttribute{Optional: true, Computed: true},
+			"cdn_proxy_host":          schema.StringAttribute{Optional: true, Computed: true},
+			"last_updated":            schema.StringAttribute{Computed: true},
+			"origins": schema.ListNestedAttribute{
+				Required: true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"l7_resource_id": schema.Int64Attribute{Computed: true},
+						"id":             schema.Int64Attribute{Computed: true},
+						"weight":         schema.Int64Attribute{Optional: true, Computed: true},
+						"mode":           schema.StringAttribute{Optional: true, Computed: true},
+						"ip":             schema.StringAttribute{Optional: true, Computed: true},
+						"created_at":     schema.Int64Attribute{Computed: true},
+						"modified_at":    schema.Int64Attribute{Computed: true},
+					},
+				},
+			},
+		},
+	}
+}
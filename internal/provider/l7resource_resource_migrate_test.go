@@ -0,0 +1,113 @@
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+func TestL7resourceUpgradeStateV0(t *testing.T) {
+	ctx := context.Background()
+	r := &l7resourceResource{}
+
+	schemaResp := &resource.SchemaResponse{}
+	r.Schema(ctx, resource.SchemaRequest{}, schemaResp)
+
+	upgrader := r.UpgradeState(ctx)[0]
+	prior := tfsdk.State{Schema: *upgrader.PriorSchema}
+	diags := prior.Set(ctx, &l7resourceResourceModelV0{
+		L7ResourceID:          types.Int64Value(42),
+		L7ResourceName:        types.StringValue("example.com"),
+		L7ResourceIsActive:    types.Int64Value(1),
+		L7ProtectionDisable:   types.Int64Value(0),
+		UseCustomSsl:          types.Int64Value(0),
+		UseLetsencryptSsl:     types.Int64Value(1),
+		CustomSslKey:          types.StringValue(""),
+		CustomSslCrt:          types.StringValue(""),
+		Forcessl:              types.Int64Value(1),
+		ServiceHTTP2:          types.Int64Value(0),
+		GeoipMode:             types.Int64Value(2),
+		GeoipList:             types.StringValue(" ru,BY "),
+		GlobalWhitelistActive: types.Int64Value(1),
+		HTTP2https:            types.Int64Value(0),
+		HTTPS2http:            types.Int64Value(0),
+		ProtectedIp:           types.StringValue("203.0.113.1"),
+		Wwwredir:              types.Int64Value(0),
+		Cdn:                   types.Int64Value(0),
+		CdnHost:               types.StringValue(""),
+		CdnProxyHost:          types.StringValue(""),
+		Origins: []*l7originResourceModelV0{{
+			L7ResourceID: types.Int64Value(42),
+			ID:           types.Int64Value(7),
+			Weight:       types.Int64Value(50),
+			Mode:         types.StringValue("primary"),
+			IP:           types.StringValue("198.51.100.1"),
+			CreatedAt:    types.Int64Value(1),
+			ModifiedAt:   types.Int64Value(2),
+		}},
+		LastUpdated: types.StringValue("Monday"),
+	})
+	if diags.HasError() {
+		t.Fatalf("could not set the prior state: %v", diags)
+	}
+
+	resp := &resource.UpgradeStateResponse{
+		State: tfsdk.State{
+			Schema: schemaResp.Schema,
+			Raw:    tftypes.NewValue(schemaResp.Schema.Type().TerraformType(ctx), nil),
+		},
+	}
+	upgrader.StateUpgrader(ctx, resource.UpgradeStateRequest{State: &prior}, resp)
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", resp.Diagnostics)
+	}
+
+	var upgraded l7resourceResourceModel
+	if diags := resp.State.Get(ctx, &upgraded); diags.HasError() {
+		t.Fatalf("could not get the upgraded state: %v", diags)
+	}
+
+	// Attributes missing in released states get their schema defaults.
+	if got := upgraded.OriginUpdateStrategy; !got.Equal(types.StringValue(originUpdateStrategyDefault)) {
+		t.Errorf("got origin_update_strategy %s, want %q", got, originUpdateStrategyDefault)
+	}
+	if got := upgraded.OriginShiftSteps; !got.Equal(types.Int64Value(defaultOriginShiftSteps)) {
+		t.Errorf("got origin_shift_steps %s, want %d", got, defaultOriginShiftSteps)
+	}
+	if got := upgraded.OriginShiftPause; !got.Equal(types.Int64Value(defaultOriginShiftPause)) {
+		t.Errorf("got origin_shift_pause %s, want %d", got, defaultOriginShiftPause)
+	}
+	if got := upgraded.AutoWeight; !got.Equal(types.BoolValue(false)) {
+		t.Errorf("got auto_weight %s, want false", got)
+	}
+	if got := upgraded.DeletionProtection; !got.Equal(types.BoolValue(false)) {
+		t.Errorf("got deletion_protection %s, want false", got)
+	}
+
+	if got := upgraded.Forcessl; !got.Equal(types.BoolValue(true)) {
+		t.Errorf("got force_ssl %s, want true", got)
+	}
+	if got := upgraded.GeoipMode; !got.Equal(types.StringValue("deny")) {
+		t.Errorf("got geoip_mode %s, want \"deny\"", got)
+	}
+	wantList := types.SetValueMust(types.StringType, []attr.Value{types.StringValue("BY"), types.StringValue("RU")})
+	if got := upgraded.GeoipList; !got.Equal(wantList) {
+		t.Errorf("got geoip_list %s, want %s", got, wantList)
+	}
+
+	if len(upgraded.Origins) != 1 {
+		t.Fatalf("got %d origins, want 1", len(upgraded.Origins))
+	}
+	origin := upgraded.Origins[0]
+	if !origin.IP.Equal(types.StringValue("198.51.100.1")) || !origin.ID.Equal(types.Int64Value(7)) {
+		t.Errorf("got origin %s with id %s, want 198.51.100.1 with id 7", origin.IP, origin.ID)
+	}
+	if !origin.Hostname.IsNull() || !origin.WeightRatio.IsNull() || !origin.ResolvedIPs.IsNull() {
+		t.Errorf("got hostname %s, weight_ratio %s and resolved_ips %s, want null", origin.Hostname, origin.WeightRatio, origin.ResolvedIPs)
+	}
+}
@@ -0,0 +1,432 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+
+	v1 "terraform-provider-servicepipe/internal/pkg/sdkv1"
+	l7origin "terraform-provider-servicepipe/internal/pkg/sdkv1/l7origin"
+	l7resource "terraform-provider-servicepipe/internal/pkg/sdkv1/l7resource"
+)
+
+// modifyL7resourcePlan runs ModifyPlan of the resource with the configuration
+// and the prior state, a nil state plans a creation. It returns the resulting
+// plan.
+func modifyL7resourcePlan(t *testing.T, r *l7resourceResource, config, state *l7resourceResourceModel) *l7resourceResourceModel {
+	t.Helper()
+	ctx := context.Background()
+
+	schemaResp := &resource.SchemaResponse{}
+	r.Schema(ctx, resource.SchemaRequest{}, schemaResp)
+	schema := schemaResp.Schema
+
+	// Set replaces the raw value, so the configuration is built as a plan.
+	configPlan := tfsdk.Plan{Schema: schema}
+	if diags := configPlan.Set(ctx, config); diags.HasError() {
+		t.Fatalf("could not set the configuration: %v", diags)
+	}
+
+	req := resource.ModifyPlanRequest{
+		Config: tfsdk.Config{Schema: schema, Raw: configPlan.Raw},
+		Plan:   tfsdk.Plan{Schema: schema, Raw: configPlan.Raw},
+		State:  tfsdk.State{Schema: schema, Raw: tftypes.NewValue(schema.Type().TerraformType(ctx), nil)},
+	}
+	if state != nil {
+		if diags := req.State.Set(ctx, state); diags.HasError() {
+			t.Fatalf("could not set the state: %v", diags)
+		}
+	}
+	resp := &resource.ModifyPlanResponse{Plan: req.Plan}
+
+	r.ModifyPlan(ctx, req, resp)
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", resp.Diagnostics)
+	}
+
+	var plan *l7resourceResourceModel
+	if diags := resp.Plan.Get(ctx, &plan); diags.HasError() {
+		t.Fatalf("could not get the plan: %v", diags)
+	}
+
+	return plan
+}
+
+// newL7resourceTestModel returns a model of the domain with null attributes.
+func newL7resourceTestModel(name types.String) *l7resourceResourceModel {
+	return &l7resourceResourceModel{
+		L7ResourceName: name,
+		GeoipList:      types.SetNull(types.StringType),
+	}
+}
+
+func TestL7resourceModifyPlanDomainNames(t *testing.T) {
+	tests := map[string]struct {
+		name        types.String
+		wantASCII   types.String
+		wantUnicode types.String
+	}{
+		"ascii": {
+			name:        types.StringValue("example.com"),
+			wantASCII:   types.StringValue("example.com"),
+			wantUnicode: types.StringValue("example.com"),
+		},
+		"unicode": {
+			name:        types.StringValue("пример.рф"),
+			wantASCII:   types.StringValue("xn--e1afmkfd.xn--p1ai"),
+			wantUnicode: types.StringValue("пример.рф"),
+		},
+		"unknown": {
+			name:        types.StringUnknown(),
+			wantASCII:   types.StringUnknown(),
+			wantUnicode: types.StringUnknown(),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			r := &l7resourceResource{resolver: newNetHostResolver()}
+			plan := modifyL7resourcePlan(t, r, newL7resourceTestModel(test.name), nil)
+
+			if !plan.L7ResourceNameASCII.Equal(test.wantASCII) {
+				t.Errorf("got l7_resource_name_ascii %s, want %s", plan.L7ResourceNameASCII, test.wantASCII)
+			}
+			if !plan.L7ResourceNameUnicode.Equal(test.wantUnicode) {
+				t.Errorf("got l7_resource_name_unicode %s, want %s", plan.L7ResourceNameUnicode, test.wantUnicode)
+			}
+		})
+	}
+}
+
+func TestL7resourceModifyPlanDeletionProtection(t *testing.T) {
+	tests := map[string]struct {
+		providerDefault bool
+		config          types.Bool
+		state           types.Bool
+		create          bool
+		want            types.Bool
+	}{
+		"create with provider default": {
+			providerDefault: true,
+			config:          types.BoolNull(),
+			create:          true,
+			want:            types.BoolValue(true),
+		},
+		"create without provider default": {
+			config: types.BoolNull(),
+			create: true,
+			want:   types.BoolValue(false),
+		},
+		"create with configuration": {
+			providerDefault: true,
+			config:          types.BoolValue(false),
+			create:          true,
+			want:            types.BoolValue(false),
+		},
+		"update keeps prior value": {
+			config: types.BoolNull(),
+			state:  types.BoolValue(true),
+			want:   types.BoolValue(true),
+		},
+		"update with configuration": {
+			config: types.BoolValue(false),
+			state:  types.BoolValue(true),
+			want:   types.BoolValue(false),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			r := &l7resourceResource{resolver: newNetHostResolver(), deletionProtection: test.providerDefault}
+
+			config := newL7resourceTestModel(types.StringValue("example.com"))
+			config.DeletionProtection = test.config
+
+			var state *l7resourceResourceModel
+			if !test.create {
+				state = newL7resourceTestModel(types.StringValue("example.com"))
+				state.DeletionProtection = test.state
+			}
+
+			plan := modifyL7resourcePlan(t, r, config, state)
+			if !plan.DeletionProtection.Equal(test.want) {
+				t.Errorf("got deletion_protection %s, want %s", plan.DeletionProtection, test.want)
+			}
+		})
+	}
+}
+
+func TestL7resourceDelete(t *testing.T) {
+	tests := map[string]struct {
+		status      int
+		body        string
+		wantSummary string
+		wantDetail  string
+	}{
+		"deleted": {
+			status: http.StatusOK,
+			body:   `{"data":{"result":"ok"}}`,
+		},
+		"unexpected result": {
+			status:      http.StatusOK,
+			body:        `{"data":{"result":"pending"}}`,
+			wantSummary: "Error Deleting l7resource",
+			wantDetail:  `Could not delete l7resource, got "pending" result`,
+		},
+		"request error": {
+			status:      http.StatusInternalServerError,
+			body:        `{"error":"internal error"}`,
+			wantSummary: "Error Deleting l7resource",
+			wantDetail:  "Could not delete l7resource, unexpected error: sp-go: got the 500 status code from the server",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(test.status)
+				_, _ = w.Write([]byte(test.body))
+			}))
+			t.Cleanup(server.Close)
+
+			r := &l7resourceResource{client: v1.NewClientV1WithCustomHTTP(server.Client(), "token", server.URL)}
+			schemaResp := &resource.SchemaResponse{}
+			r.Schema(ctx, resource.SchemaRequest{}, schemaResp)
+
+			model := newL7resourceTestModel(types.StringValue("example.com"))
+			model.L7ResourceID = types.Int64Value(1)
+			state := tfsdk.State{Schema: schemaResp.Schema}
+			if diags := state.Set(ctx, model); diags.HasError() {
+				t.Fatalf("could not set the state: %v", diags)
+			}
+
+			resp := &resource.DeleteResponse{State: state}
+			r.Delete(ctx, resource.DeleteRequest{State: state}, resp)
+
+			if test.wantSummary == "" {
+				if resp.Diagnostics.HasError() {
+					t.Fatalf("unexpected diagnostics: %v", resp.Diagnostics)
+				}
+				return
+			}
+
+			errs := resp.Diagnostics.Errors()
+			if len(errs) != 1 {
+				t.Fatalf("got diagnostics %v, want a single error", resp.Diagnostics)
+			}
+			if errs[0].Summary() != test.wantSummary || !strings.HasPrefix(errs[0].Detail(), test.wantDetail) {
+				t.Errorf("got error %q: %q, want %q: %q", errs[0].Summary(), errs[0].Detail(), test.wantSummary, test.wantDetail)
+			}
+		})
+	}
+}
+
+// fakeL7resourceAPI serves a single domain and its origins. Like the API, it
+// changes modifiedAt of the domain when the domain or one of its origins
+// changes.
+type fakeL7resourceAPI struct {
+	mu         sync.Mutex
+	domain     l7resource.Item
+	origins    map[int64]l7origin.Item
+	nextID     int64
+	domainPuts int
+	originPuts int
+}
+
+// newFakeL7resourceAPI returns a client of a fake API holding the domain and
+// its origins.
+func newFakeL7resourceAPI(t *testing.T, domain l7resource.Item, origins []l7origin.Item) (*fakeL7resourceAPI, *v1.Client) {
+	t.Helper()
+
+	api := &fakeL7resourceAPI{domain: domain, origins: make(map[int64]l7origin.Item)}
+	for _, origin := range origins {
+		api.origins[origin.ID] = origin
+		api.nextID = max(api.nextID, origin.ID)
+	}
+
+	server := httptest.NewServer(api)
+	t.Cleanup(server.Close)
+
+	return api, v1.NewClientV1WithCustomHTTP(server.Client(), "token", server.URL)
+}
+
+func (api *fakeL7resourceAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+
+	var response any
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/l7/resource/"+strconv.FormatInt(api.domain.L7ResourceID, 10):
+		response = l7resource.Data{Data: l7resource.Result{Result: api.domain}}
+	case r.Method == http.MethodPut && r.URL.Path == "/l7/resource":
+		if err := json.NewDecoder(r.Body).Decode(&api.domain); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		api.domain.ModifiedAt++
+		api.domainPuts++
+		response = l7resource.Data{Data: l7resource.Result{Result: api.domain}}
+	case r.Method == http.MethodGet && r.URL.Path == "/l7/origin":
+		body := l7origin.DataItems{}
+		for id := int64(1); id <= api.nextID; id++ {
+			if origin, ok := api.origins[id]; ok {
+				body.DataItems.ResultItems.Items = append(body.DataItems.ResultItems.Items, origin)
+			}
+		}
+		body.DataItems.ResultItems.Info.TotalCount = int64(len(api.origins))
+		response = body
+	case r.Method == http.MethodPut && r.URL.Path == "/l7/origin":
+		var item l7origin.Item
+		if err := json.NewDecoder(r.Body).Decode(&item); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		api.origins[item.ID] = item
+		api.domain.ModifiedAt++
+		api.originPuts++
+		response = l7origin.Data{Data: l7origin.Result{Result: item}}
+	default:
+		http.Error(w, "unexpected request", http.StatusNotFound)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// newL7resourceStateModel returns the state of the domain and its origins as
+// the resource stores it.
+func newL7resourceStateModel(domain l7resource.Item, origins []l7origin.Item) *l7resourceResourceModel {
+	model := flatternL7ResourceModel(domain)
+	model.OriginUpdateStrategy = types.StringValue(originUpdateStrategyDefault)
+	model.OriginShiftSteps = types.Int64Value(defaultOriginShiftSteps)
+	model.OriginShiftPause = types.Int64Value(defaultOriginShiftPause)
+	model.AutoWeight = types.BoolValue(false)
+	model.DeletionProtection = types.BoolValue(false)
+	model.LastUpdated = types.StringValue("Monday")
+	for i := range origins {
+		origin := origins[i]
+		origin.L7ResourceID = domain.L7ResourceID
+		model.Origins = append(model.Origins, flatternL7OriginModel(&origin))
+	}
+
+	return model
+}
+
+// updateL7resource runs Update of the resource from the state to the plan and
+// returns the response.
+func updateL7resource(t *testing.T, r *l7resourceResource, state, plan *l7resourceResourceModel) *resource.UpdateResponse {
+	t.Helper()
+	ctx := context.Background()
+
+	schemaResp := &resource.SchemaResponse{}
+	r.Schema(ctx, resource.SchemaRequest{}, schemaResp)
+
+	req := resource.UpdateRequest{
+		State: tfsdk.State{Schema: schemaResp.Schema},
+		Plan:  tfsdk.Plan{Schema: schemaResp.Schema},
+	}
+	if diags := req.State.Set(ctx, state); diags.HasError() {
+		t.Fatalf("could not set the state: %v", diags)
+	}
+	if diags := req.Plan.Set(ctx, plan); diags.HasError() {
+		t.Fatalf("could not set the plan: %v", diags)
+	}
+
+	resp := &resource.UpdateResponse{State: req.State}
+	r.Update(ctx, req, resp)
+
+	return resp
+}
+
+func TestL7resourceUpdateModifiedAt(t *testing.T) {
+	ctx := context.Background()
+	domain := l7resource.Item{L7ResourceID: 1, L7ResourceName: "example.com", L7ResourceIsActive: 1, GlobalWhitelistActive: 1, ModifiedAt: 1000}
+	origins := []l7origin.Item{
+		{ID: 1, IP: "198.51.100.1", Weight: 100, Mode: l7origin.ModePrimary},
+		{ID: 2, IP: "198.51.100.2", Weight: 0, Mode: l7origin.ModePrimary},
+	}
+	api, client := newFakeL7resourceAPI(t, domain, origins)
+	r := &l7resourceResource{client: client, maxParallelRequests: 1, resolver: fakeHostResolver{}}
+
+	// The settings and the origins change in the same apply, origin changes
+	// modify the domain after its settings were updated.
+	state := newL7resourceStateModel(domain, origins)
+	plan := newL7resourceStateModel(domain, origins)
+	plan.Wwwredir = types.BoolValue(true)
+	plan.Origins[0].Weight = types.Int64Value(60)
+	plan.Origins[1].Weight = types.Int64Value(40)
+	plan.ModifiedAt = types.Int64Unknown()
+
+	resp := updateL7resource(t, r, state, plan)
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", resp.Diagnostics)
+	}
+
+	var updated *l7resourceResourceModel
+	if diags := resp.State.Get(ctx, &updated); diags.HasError() {
+		t.Fatalf("could not get the state: %v", diags)
+	}
+	if got, want := updated.ModifiedAt, types.Int64Value(int64(api.domain.ModifiedAt)); !got.Equal(want) {
+		t.Fatalf("got modified_at %s, want %s of the domain after the origin changes", got, want)
+	}
+	if api.originPuts != 2 || api.domain.Wwwredir != 1 {
+		t.Fatalf("got %d origin updates and wwwredir %d, want 2 and 1", api.originPuts, api.domain.Wwwredir)
+	}
+
+	// The next apply starts from the stored state without a refresh and is
+	// not rejected as a concurrent modification.
+	next := *updated
+	next.HTTP2https = types.BoolValue(true)
+	next.ModifiedAt = types.Int64Unknown()
+	resp = updateL7resource(t, r, updated, &next)
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected diagnostics of the next update: %v", resp.Diagnostics)
+	}
+	if api.domain.HTTP2https != 1 {
+		t.Errorf("got http2https %d, want 1", api.domain.HTTP2https)
+	}
+}
+
+func TestL7resourceUpdateConflict(t *testing.T) {
+	domain := l7resource.Item{L7ResourceID: 1, L7ResourceName: "example.com", L7ResourceIsActive: 1, GlobalWhitelistActive: 1, ModifiedAt: 1000}
+	origins := []l7origin.Item{{ID: 1, IP: "198.51.100.1", Weight: 100, Mode: l7origin.ModePrimary}}
+
+	// The domain was modified outside of Terraform after the last refresh.
+	modified := domain
+	modified.ModifiedAt = 2000
+	api, client := newFakeL7resourceAPI(t, modified, origins)
+	r := &l7resourceResource{client: client, maxParallelRequests: 1, resolver: fakeHostResolver{}}
+
+	state := newL7resourceStateModel(domain, origins)
+	plan := newL7resourceStateModel(domain, origins)
+	plan.Wwwredir = types.BoolValue(true)
+	plan.Origins[0].Weight = types.Int64Value(50)
+	plan.ModifiedAt = types.Int64Unknown()
+
+	resp := updateL7resource(t, r, state, plan)
+	errs := resp.Diagnostics.Errors()
+	if len(errs) != 1 {
+		t.Fatalf("got diagnostics %v, want a single error", resp.Diagnostics)
+	}
+	if errs[0].Summary() != "Error Updating Servicepipe l7 resource" || !strings.Contains(errs[0].Detail(), l7resource.ErrConcurrentModification.Error()) {
+		t.Errorf("got error %q: %q, want a concurrent modification", errs[0].Summary(), errs[0].Detail())
+	}
+
+	// Nothing is changed once the conflict is found.
+	if api.domainPuts != 0 || api.originPuts != 0 {
+		t.Errorf("got %d domain and %d origin updates, want none", api.domainPuts, api.originPuts)
+	}
+}
@@ -0,0 +1,277 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	l7origin "terraform-provider-servicepipe/internal/pkg/sdkv1/l7origin"
+)
+
+var _ resource.ConfigValidator = l7originsValidator{}
+
+// l7originsValidator validates the origins of a l7 resource as a whole: origin
+// IPs and hostnames must be unique and at least one origin must be primary.
+type l7originsValidator struct{}
+
+// Description describes the validation in plain text formatting.
+func (v l7originsValidator) Description(_ context.Context) string {
+	return "origins must have unique IPs and hostnames and at least one primary origin"
+}
+
+// MarkdownDescription describes the validation in Markdown formatting.
+func (v l7originsValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+// ValidateResource performs the validation.
+func (v l7originsValidator) ValidateResource(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var origins types.List
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("origins"), &origins)...)
+	if resp.Diagnostics.HasError() || origins.IsNull() || origins.IsUnknown() {
+		return
+	}
+
+	var models []*l7originResourceModel
+	resp.Diagnostics.Append(origins.ElementsAs(ctx, &models, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	hasPrimary := false
+	modeKnown := true
+	seen := make(map[string]int, len(models))
+	for i, origin := range models {
+		if origin == nil {
+			continue
+		}
+
+		switch {
+		case origin.Mode.IsUnknown():
+			modeKnown = false
+		case origin.Mode.IsNull(), origin.Mode.ValueString() == l7origin.ModePrimary:
+			// Mode defaults to primary.
+			hasPrimary = true
+		}
+
+		for _, attr := range []struct {
+			name  string
+			value types.String
+		}{
+			{name: "ip", value: origin.IP},
+			{name: "hostname", value: origin.Hostname},
+		} {
+			if attr.value.IsNull() || attr.value.IsUnknown() {
+				continue
+			}
+
+			key := attr.name + "/" + attr.value.ValueString()
+			if first, ok := seen[key]; ok {
+				resp.Diagnostics.AddAttributeError(
+					path.Root("origins").AtListIndex(i).AtName(attr.name),
+					"Duplicate Origin",
+					fmt.Sprintf("Origin %s %q is already used by origin %d, each origin must be unique.", attr.name, attr.value.ValueString(), first),
+				)
+				continue
+			}
+			seen[key] = i
+		}
+	}
+
+	if !hasPrimary && modeKnown && len(models) > 0 {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("origins"),
+			"Missing Primary Origin",
+			fmt.Sprintf("At least one origin must have the %q mode.", l7origin.ModePrimary),
+		)
+	}
+}
+
+var _ resource.ConfigValidator = l7originWeightsValidator{}
+
+// l7originWeightsValidator validates that origin weights are either set by hand
+// or computed with auto_weight, but not both.
+type l7originWeightsValidator struct{}
+
+// Description describes the validation in plain text formatting.
+func (v l7originWeightsValidator) Description(_ context.Context) string {
+	return "origin weight conflicts with auto_weight, origin weight_ratio requires auto_weight"
+}
+
+// MarkdownDescription describes the validation in Markdown formatting.
+func (v l7originWeightsValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+// ValidateResource performs the validation.
+func (v l7originWeightsValidator) ValidateResource(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var autoWeight types.Bool
+	var origins types.List
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("auto_weight"), &autoWeight)...)
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("origins"), &origins)...)
+	if resp.Diagnostics.HasError() || autoWeight.IsUnknown() || origins.IsNull() || origins.IsUnknown() {
+		return
+	}
+
+	var models []*l7originResourceModel
+	resp.Diagnostics.Append(origins.ElementsAs(ctx, &models, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	for i, origin := range models {
+		if origin == nil {
+			continue
+		}
+
+		if autoWeight.ValueBool() && !origin.Weight.IsNull() {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("origins").AtListIndex(i).AtName("weight"),
+				"Conflicting Origin Weight",
+				"Origin weight is computed when auto_weight is enabled, use weight_ratio to change the share of the origin.",
+			)
+		}
+
+		if !autoWeight.ValueBool() && !origin.WeightRatio.IsNull() {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("origins").AtListIndex(i).AtName("weight_ratio"),
+				"Unused Origin Weight Ratio",
+				"Origin weight_ratio is only used when auto_weight is enabled.",
+			)
+		}
+	}
+}
+
+var _ resource.ConfigValidator = l7resourceSettingsValidator{}
+
+// l7resourceSettingsValidator validates that l7 resource settings are consistent
+// with each other: redirects don't loop, a single SSL source is used and
+// enabled features have the values they depend on.
+type l7resourceSettingsValidator struct{}
+
+// l7resourceSettings contains l7 resource settings checked by
+// l7resourceSettingsValidator.
+type l7resourceSettings struct {
+	UseCustomSsl      types.Bool
+	UseLetsencryptSsl types.Bool
+	CustomSslKey      types.String
+	CustomSslCrt      types.String
+	Forcessl          types.Bool
+	GeoipMode         types.String
+	GeoipList         types.Set
+	HTTP2https        types.Bool
+	HTTPS2http        types.Bool
+	Cdn               types.Bool
+	CdnHost           types.String
+}
+
+// Description describes the validation in plain text formatting.
+func (v l7resourceSettingsValidator) Description(_ context.Context) string {
+	return "l7 resource settings must not contradict each other"
+}
+
+// MarkdownDescription describes the validation in Markdown formatting.
+func (v l7resourceSettingsValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+// ValidateResource performs the validation.
+func (v l7resourceSettingsValidator) ValidateResource(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var settings l7resourceSettings
+	for name, target := range map[string]any{
+		"use_custom_ssl":      &settings.UseCustomSsl,
+		"use_letsencrypt_ssl": &settings.UseLetsencryptSsl,
+		"custom_ssl_key":      &settings.CustomSslKey,
+		"custom_ssl_crt":      &settings.CustomSslCrt,
+		"force_ssl":           &settings.Forcessl,
+		"geoip_mode":          &settings.GeoipMode,
+		"geoip_list":          &settings.GeoipList,
+		"http_2_https":        &settings.HTTP2https,
+		"https_2_http":        &settings.HTTPS2http,
+		"cdn":                 &settings.Cdn,
+		"cdn_host":            &settings.CdnHost,
+	} {
+		resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root(name), target)...)
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if isTrue(settings.HTTP2https) && isTrue(settings.HTTPS2http) {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("https_2_http"),
+			"Conflicting Redirects",
+			"http_2_https and https_2_http can't be enabled together, the site would redirect in a loop.",
+		)
+	}
+
+	if isTrue(settings.UseCustomSsl) && isTrue(settings.UseLetsencryptSsl) {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("use_letsencrypt_ssl"),
+			"Conflicting SSL Certificates",
+			"use_custom_ssl and use_letsencrypt_ssl can't be enabled together, choose a single certificate source.",
+		)
+	}
+
+	if isTrue(settings.UseCustomSsl) {
+		for _, attr := range []struct {
+			name  string
+			value types.String
+		}{
+			{name: "custom_ssl_key", value: settings.CustomSslKey},
+			{name: "custom_ssl_crt", value: settings.CustomSslCrt},
+		} {
+			if isEmpty(attr.value) {
+				resp.Diagnostics.AddAttributeError(
+					path.Root(attr.name),
+					"Missing Custom SSL Certificate",
+					fmt.Sprintf("%s must be set when use_custom_ssl is enabled.", attr.name),
+				)
+			}
+		}
+	}
+
+	if isTrue(settings.Forcessl) && isFalse(settings.UseCustomSsl) && isFalse(settings.UseLetsencryptSsl) {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("force_ssl"),
+			"Missing SSL Certificate",
+			"force_ssl requires a certificate, enable use_custom_ssl or use_letsencrypt_ssl.",
+		)
+	}
+
+	if isTrue(settings.Cdn) && isEmpty(settings.CdnHost) {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("cdn_host"),
+			"Missing CDN Host",
+			"cdn_host must be set when cdn is enabled.",
+		)
+	}
+
+	if !settings.GeoipMode.IsNull() && !settings.GeoipMode.IsUnknown() && settings.GeoipMode.ValueString() != geoipModeOff &&
+		!settings.GeoipList.IsUnknown() && len(settings.GeoipList.Elements()) == 0 {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("geoip_list"),
+			"Missing GeoIP List",
+			fmt.Sprintf("geoip_list must not be empty when geoip_mode is not %q.", geoipModeOff),
+		)
+	}
+}
+
+// isTrue reports whether the value is known to be true.
+func isTrue(value types.Bool) bool {
+	return !value.IsUnknown() && value.ValueBool()
+}
+
+// isFalse reports whether the value is known to be false, null values fall
+// back to the false default.
+func isFalse(value types.Bool) bool {
+	return !value.IsUnknown() && !value.ValueBool()
+}
+
+// isEmpty reports whether the value is known to be empty.
+func isEmpty(value types.String) bool {
+	return !value.IsUnknown() && value.ValueString() == ""
+}
@@ -0,0 +1,241 @@
+package provider
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestL7resourceSettingsValidator(t *testing.T) {
+	tests := map[string]struct {
+		config    func(model *l7resourceResourceModel)
+		wantPaths []string
+	}{
+		"no settings": {
+			config: func(model *l7resourceResourceModel) {},
+		},
+		"redirect loop": {
+			config: func(model *l7resourceResourceModel) {
+				model.HTTP2https = types.BoolValue(true)
+				model.HTTPS2http = types.BoolValue(true)
+			},
+			wantPaths: []string{"https_2_http"},
+		},
+		"single redirect": {
+			config: func(model *l7resourceResourceModel) {
+				model.HTTP2https = types.BoolValue(true)
+				model.HTTPS2http = types.BoolValue(false)
+			},
+		},
+		"redirect with unknown redirect": {
+			config: func(model *l7resourceResourceModel) {
+				model.HTTP2https = types.BoolValue(true)
+				model.HTTPS2http = types.BoolUnknown()
+			},
+		},
+		"both certificate sources": {
+			config: func(model *l7resourceResourceModel) {
+				model.UseCustomSsl = types.BoolValue(true)
+				model.UseLetsencryptSsl = types.BoolValue(true)
+				model.CustomSslKey = types.StringValue("key")
+				model.CustomSslCrt = types.StringValue("crt")
+			},
+			wantPaths: []string{"use_letsencrypt_ssl"},
+		},
+		"custom SSL with unknown Let's Encrypt": {
+			config: func(model *l7resourceResourceModel) {
+				model.UseCustomSsl = types.BoolValue(true)
+				model.UseLetsencryptSsl = types.BoolUnknown()
+				model.CustomSslKey = types.StringValue("key")
+				model.CustomSslCrt = types.StringValue("crt")
+			},
+		},
+		"custom SSL without key and certificate": {
+			config: func(model *l7resourceResourceModel) {
+				model.UseCustomSsl = types.BoolValue(true)
+			},
+			wantPaths: []string{"custom_ssl_crt", "custom_ssl_key"},
+		},
+		"custom SSL with empty key": {
+			config: func(model *l7resourceResourceModel) {
+				model.UseCustomSsl = types.BoolValue(true)
+				model.CustomSslKey = types.StringValue("")
+				model.CustomSslCrt = types.StringValue("crt")
+			},
+			wantPaths: []string{"custom_ssl_key"},
+		},
+		"custom SSL with unknown key and certificate": {
+			config: func(model *l7resourceResourceModel) {
+				model.UseCustomSsl = types.BoolValue(true)
+				model.CustomSslKey = types.StringUnknown()
+				model.CustomSslCrt = types.StringUnknown()
+			},
+		},
+		"unknown custom SSL without key": {
+			config: func(model *l7resourceResourceModel) {
+				model.UseCustomSsl = types.BoolUnknown()
+			},
+		},
+		"key without custom SSL": {
+			config: func(model *l7resourceResourceModel) {
+				model.UseCustomSsl = types.BoolValue(false)
+				model.CustomSslKey = types.StringValue("key")
+			},
+		},
+		"force SSL without certificate": {
+			config: func(model *l7resourceResourceModel) {
+				model.Forcessl = types.BoolValue(true)
+				model.UseCustomSsl = types.BoolValue(false)
+				model.UseLetsencryptSsl = types.BoolValue(false)
+			},
+			wantPaths: []string{"force_ssl"},
+		},
+		"force SSL with null certificate sources": {
+			config: func(model *l7resourceResourceModel) {
+				model.Forcessl = types.BoolValue(true)
+			},
+			wantPaths: []string{"force_ssl"},
+		},
+		"force SSL with unknown certificate source": {
+			config: func(model *l7resourceResourceModel) {
+				model.Forcessl = types.BoolValue(true)
+				model.UseLetsencryptSsl = types.BoolUnknown()
+			},
+		},
+		"force SSL with Let's Encrypt": {
+			config: func(model *l7resourceResourceModel) {
+				model.Forcessl = types.BoolValue(true)
+				model.UseLetsencryptSsl = types.BoolValue(true)
+			},
+		},
+		"unknown force SSL": {
+			config: func(model *l7resourceResourceModel) {
+				model.Forcessl = types.BoolUnknown()
+			},
+		},
+		"CDN without host": {
+			config: func(model *l7resourceResourceModel) {
+				model.Cdn = types.BoolValue(true)
+			},
+			wantPaths: []string{"cdn_host"},
+		},
+		"CDN with empty host": {
+			config: func(model *l7resourceResourceModel) {
+				model.Cdn = types.BoolValue(true)
+				model.CdnHost = types.StringValue("")
+			},
+			wantPaths: []string{"cdn_host"},
+		},
+		"CDN with unknown host": {
+			config: func(model *l7resourceResourceModel) {
+				model.Cdn = types.BoolValue(true)
+				model.CdnHost = types.StringUnknown()
+			},
+		},
+		"CDN with host": {
+			config: func(model *l7resourceResourceModel) {
+				model.Cdn = types.BoolValue(true)
+				model.CdnHost = types.StringValue("cdn.example.com")
+			},
+		},
+		"unknown CDN without host": {
+			config: func(model *l7resourceResourceModel) {
+				model.Cdn = types.BoolUnknown()
+			},
+		},
+		"GeoIP filtering without list": {
+			config: func(model *l7resourceResourceModel) {
+				model.GeoipMode = types.StringValue(geoipModeAllow)
+			},
+			wantPaths: []string{"geoip_list"},
+		},
+		"GeoIP filtering with empty list": {
+			config: func(model *l7resourceResourceModel) {
+				model.GeoipMode = types.StringValue(geoipModeDeny)
+				model.GeoipList = newGeoipList()
+			},
+			wantPaths: []string{"geoip_list"},
+		},
+		"GeoIP filtering with unknown list": {
+			config: func(model *l7resourceResourceModel) {
+				model.GeoipMode = types.StringValue(geoipModeAllow)
+				model.GeoipList = types.SetUnknown(types.StringType)
+			},
+		},
+		"GeoIP filtering with list": {
+			config: func(model *l7resourceResourceModel) {
+				model.GeoipMode = types.StringValue(geoipModeDeny)
+				model.GeoipList = newGeoipList("RU")
+			},
+		},
+		"GeoIP off without list": {
+			config: func(model *l7resourceResourceModel) {
+				model.GeoipMode = types.StringValue(geoipModeOff)
+			},
+		},
+		"unknown GeoIP mode without list": {
+			config: func(model *l7resourceResourceModel) {
+				model.GeoipMode = types.StringUnknown()
+			},
+		},
+		"several conflicts": {
+			config: func(model *l7resourceResourceModel) {
+				model.HTTP2https = types.BoolValue(true)
+				model.HTTPS2http = types.BoolValue(true)
+				model.Forcessl = types.BoolValue(true)
+				model.Cdn = types.BoolValue(true)
+			},
+			wantPaths: []string{"cdn_host", "force_ssl", "https_2_http"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			model := newL7resourceTestModel(types.StringValue("example.com"))
+			test.config(model)
+
+			diags := validateL7resourceSettings(t, model)
+			var got []string
+			for _, d := range diags.Errors() {
+				withPath, ok := d.(diag.DiagnosticWithPath)
+				if !ok {
+					t.Fatalf("got error %q without an attribute path", d.Summary())
+				}
+				got = append(got, withPath.Path().String())
+			}
+			sort.Strings(got)
+
+			if !reflect.DeepEqual(got, test.wantPaths) {
+				t.Errorf("got errors of %q, want %q: %v", got, test.wantPaths, diags)
+			}
+		})
+	}
+}
+
+// validateL7resourceSettings runs l7resourceSettingsValidator on the
+// configuration.
+func validateL7resourceSettings(t *testing.T, config *l7resourceResourceModel) diag.Diagnostics {
+	t.Helper()
+	ctx := context.Background()
+
+	schemaResp := &resource.SchemaResponse{}
+	(&l7resourceResource{}).Schema(ctx, resource.SchemaRequest{}, schemaResp)
+
+	// Set replaces the raw value, so the configuration is built as a plan.
+	configPlan := tfsdk.Plan{Schema: schemaResp.Schema}
+	if diags := configPlan.Set(ctx, config); diags.HasError() {
+		t.Fatalf("could not set the configuration: %v", diags)
+	}
+
+	req := resource.ValidateConfigRequest{Config: tfsdk.Config{Schema: schemaResp.Schema, Raw: configPlan.Raw}}
+	resp := &resource.ValidateConfigResponse{}
+	l7resourceSettingsValidator{}.ValidateResource(ctx, req, resp)
+
+	return resp.Diagnostics
+}
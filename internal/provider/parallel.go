@@ -0,0 +1,62 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+)
+
+// defaultMaxParallelRequests is the default number of API requests a single
+// operation runs at the same time.
+const defaultMaxParallelRequests = 4
+
+// runParallel calls fn for every index in [0, n) running at most limit calls at
+// the same time. All calls are made even when some of them fail, the errors are
+// joined in the index order. Calls are no longer started once ctx is done, the
+// cancellation is then reported once after the errors of started calls.
+func runParallel(ctx context.Context, limit, n int, fn func(ctx context.Context, i int) error) error {
+	if limit < 1 {
+		limit = 1
+	}
+
+	errs := make([]error, n)
+	var canceled error
+	sem := make(chan struct{}, limit)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		if ctx.Err() == nil {
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+			}
+		}
+		if canceled = ctx.Err(); canceled != nil {
+			break
+		}
+
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			errs[i] = fn(ctx, i)
+		}(i)
+	}
+	wg.Wait()
+
+	return errors.Join(append(errs, canceled)...)
+}
+
+// appendErrorDiagnostics adds an error diagnostic for every error joined in err.
+func appendErrorDiagnostics(diags *diag.Diagnostics, summary string, err error) {
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		for _, e := range joined.Unwrap() {
+			appendErrorDiagnostics(diags, summary, e)
+		}
+		return
+	}
+
+	diags.AddError(summary, err.Error())
+}
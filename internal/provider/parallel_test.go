@@ -0,0 +1,139 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRunParallelLimit(t *testing.T) {
+	tests := map[string]struct {
+		limit   int
+		n       int
+		wantMax int32
+	}{
+		"limited":              {limit: 3, n: 20, wantMax: 3},
+		"limit above calls":    {limit: 10, n: 4, wantMax: 4},
+		"zero limit is serial": {limit: 0, n: 5, wantMax: 1},
+		"no calls":             {limit: 3, n: 0, wantMax: 0},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var active, maxActive, calls atomic.Int32
+			err := runParallel(context.Background(), test.limit, test.n, func(_ context.Context, _ int) error {
+				current := active.Add(1)
+				defer active.Add(-1)
+				calls.Add(1)
+
+				for {
+					previous := maxActive.Load()
+					if current <= previous || maxActive.CompareAndSwap(previous, current) {
+						break
+					}
+				}
+				time.Sleep(time.Millisecond)
+
+				return nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := calls.Load(); got != int32(test.n) {
+				t.Errorf("got %d calls, want %d", got, test.n)
+			}
+			if got := maxActive.Load(); got > test.wantMax {
+				t.Errorf("got %d calls at the same time, want at most %d", got, test.wantMax)
+			}
+		})
+	}
+}
+
+func TestRunParallelErrors(t *testing.T) {
+	errFailed := errors.New("failed")
+
+	var mu sync.Mutex
+	called := make(map[int]bool)
+	err := runParallel(context.Background(), 2, 6, func(_ context.Context, i int) error {
+		mu.Lock()
+		called[i] = true
+		mu.Unlock()
+
+		if i == 1 || i == 4 {
+			return fmt.Errorf("call %d: %w", i, errFailed)
+		}
+		return nil
+	})
+
+	// Failed calls don't stop the others.
+	if len(called) != 6 {
+		t.Errorf("got calls %v, want all 6", called)
+	}
+	if !errors.Is(err, errFailed) {
+		t.Fatalf("got error %v, want %v", err, errFailed)
+	}
+	if got, want := err.Error(), "call 1: failed\ncall 4: failed"; got != want {
+		t.Errorf("got error %q, want %q", got, want)
+	}
+}
+
+func TestRunParallelCancel(t *testing.T) {
+	t.Run("during calls", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		t.Cleanup(cancel)
+
+		errFailed := errors.New("failed")
+		var mu sync.Mutex
+		var called []int
+		err := runParallel(ctx, 1, 5, func(_ context.Context, i int) error {
+			mu.Lock()
+			called = append(called, i)
+			mu.Unlock()
+
+			switch i {
+			case 0:
+				return errFailed
+			case 1:
+				cancel()
+			}
+			return nil
+		})
+
+		if len(called) != 2 {
+			t.Errorf("got calls %v, want 0 and 1 only", called)
+		}
+
+		joined, ok := err.(interface{ Unwrap() []error })
+		if !ok {
+			t.Fatalf("got error %v, want joined errors", err)
+		}
+		errs := joined.Unwrap()
+		if len(errs) != 2 || !errors.Is(errs[0], errFailed) || !errors.Is(errs[1], context.Canceled) {
+			t.Errorf("got errors %v, want the failed call and a single cancellation", errs)
+		}
+	})
+
+	t.Run("before calls", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		calls := 0
+		err := runParallel(ctx, 4, 10, func(_ context.Context, _ int) error {
+			calls++
+			return nil
+		})
+
+		if calls != 0 {
+			t.Errorf("got %d calls, want none", calls)
+		}
+		joined, ok := err.(interface{ Unwrap() []error })
+		if !ok || len(joined.Unwrap()) != 1 || !errors.Is(err, context.Canceled) {
+			t.Errorf("got error %v, want a single cancellation", err)
+		}
+	})
+}
@@ -5,14 +5,19 @@ package provider
 
 import (
 	"context"
+	"os"
+	"time"
 
 	v1 "terraform-provider-servicepipe/internal/pkg/sdkv1"
 
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/provider"
 	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 )
@@ -26,6 +31,9 @@ type servicepipeProvider struct {
 	// provider is built and ran locally, and "test" when running acceptance
 	// testing.
 	version string
+
+	// auditLog is the audit log of the configured clients.
+	auditLog auditLog
 }
 
 // New is a helper function to simplify provider server and testing implementation.
@@ -39,8 +47,33 @@ func New(version string) func() provider.Provider {
 
 // servicepipeProviderModel describes the provider data model.
 type servicepipeProviderModel struct {
-	Endpoint types.String `tfsdk:"endpoint"`
-	Token    types.String `tfsdk:"token"`
+	Endpoint            types.String `tfsdk:"endpoint"`
+	Token               types.String `tfsdk:"token"`
+	DeletionProtection  types.Bool   `tfsdk:"deletion_protection"`
+	MaxParallelRequests types.Int64  `tfsdk:"max_parallel_requests"`
+	AuditLogPath        types.String `tfsdk:"audit_log_path"`
+
+	CircuitBreakerThreshold types.Int64 `tfsdk:"circuit_breaker_threshold"`
+	CircuitBreakerCoolDown  types.Int64 `tfsdk:"circuit_breaker_cool_down"`
+
+	CACertPEM          types.String `tfsdk:"ca_cert_pem"`
+	CACertFile         types.String `tfsdk:"ca_cert_file"`
+	ClientCert         types.String `tfsdk:"client_cert"`
+	ClientKey          types.String `tfsdk:"client_key"`
+	ProxyURL           types.String `tfsdk:"proxy_url"`
+	InsecureSkipVerify types.Bool   `tfsdk:"insecure_skip_verify"`
+}
+
+// servicepipeProviderData is passed to resources and data sources on configure.
+type servicepipeProviderData struct {
+	client *v1.Client
+
+	// deletionProtection is the deletion_protection default of new l7 resources.
+	deletionProtection bool
+
+	// maxParallelRequests is the maximum number of API requests a single
+	// operation runs at the same time.
+	maxParallelRequests int
 }
 
 // Metadata returns the provider type name.
@@ -62,6 +95,82 @@ func (p *servicepipeProvider) Schema(ctx context.Context, req provider.SchemaReq
 				Sensitive:   true,
 				Description: "Service api token",
 			},
+			"deletion_protection": schema.BoolAttribute{
+				Optional: true,
+				MarkdownDescription: "Default value of `deletion_protection` for new `servicepipe_l7resource` resources. " +
+					"Defaults to `false`.",
+			},
+			"max_parallel_requests": schema.Int64Attribute{
+				Optional: true,
+				MarkdownDescription: "Maximum number of independent API requests, such as origin changes of a domain, " +
+					"a single operation runs at the same time. Defaults to `4`.",
+				Validators: []validator.Int64{
+					int64validator.AtLeast(1),
+				},
+			},
+			"audit_log_path": schema.StringAttribute{
+				Optional: true,
+				MarkdownDescription: "Path of a file every create, update and delete API call of l7 resources and origins " +
+					"is appended to as a JSON line with the time, the resource, the target IDs, the changed fields and the outcome. " +
+					"Values of SSL keys and certificates are redacted.",
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+				},
+			},
+			"circuit_breaker_threshold": schema.Int64Attribute{
+				Optional: true,
+				MarkdownDescription: "Number of consecutive API requests failing with a timeout, a connection error or a 5xx status code " +
+					"after which requests fail fast for `circuit_breaker_cool_down` seconds instead of waiting for the degraded API. " +
+					"Set to `0` to disable the circuit breaker. Defaults to `5`.",
+				Validators: []validator.Int64{
+					int64validator.AtLeast(0),
+				},
+			},
+			"circuit_breaker_cool_down": schema.Int64Attribute{
+				Optional: true,
+				MarkdownDescription: "Seconds requests fail fast for once the circuit breaker is open, " +
+					"afterwards a single probe request decides whether the API is used again. Defaults to `30`.",
+				Validators: []validator.Int64{
+					int64validator.AtLeast(1),
+				},
+			},
+			"ca_cert_pem": schema.StringAttribute{
+				Optional: true,
+				MarkdownDescription: "PEM encoded CA certificates trusted in addition to the system trust store, " +
+					"such as the one of a TLS intercepting proxy. Conflicts with `ca_cert_file`.",
+				Validators: []validator.String{
+					stringvalidator.ConflictsWith(path.MatchRoot("ca_cert_file")),
+				},
+			},
+			"ca_cert_file": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Path of a file with PEM encoded CA certificates trusted in addition to the system trust store.",
+			},
+			"client_cert": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "PEM encoded certificate the client authenticates with, such as to an API gateway requiring mTLS.",
+				Validators: []validator.String{
+					stringvalidator.AlsoRequires(path.MatchRoot("client_key")),
+				},
+			},
+			"client_key": schema.StringAttribute{
+				Optional:            true,
+				Sensitive:           true,
+				MarkdownDescription: "PEM encoded private key of `client_cert`.",
+				Validators: []validator.String{
+					stringvalidator.AlsoRequires(path.MatchRoot("client_cert")),
+				},
+			},
+			"proxy_url": schema.StringAttribute{
+				Optional: true,
+				MarkdownDescription: "URL of the HTTP, HTTPS or SOCKS5 proxy API requests are sent through. " +
+					"The `HTTPS_PROXY` and `NO_PROXY` environment variables are used when it is not set.",
+			},
+			"insecure_skip_verify": schema.BoolAttribute{
+				Optional: true,
+				MarkdownDescription: "Disable verification of the API server certificate. " +
+					"Anyone on the network path can read the API token, use it only for testing. Defaults to `false`.",
+			},
 		},
 	}
 }
@@ -127,8 +236,74 @@ func (p *servicepipeProvider) Configure(ctx context.Context, req provider.Config
 
 	tflog.Debug(ctx, "Creating servicepipe client")
 
+	transportOpts := &v1.TransportOpts{
+		CACertPEM:          []byte(config.CACertPEM.ValueString()),
+		ClientCertPEM:      []byte(config.ClientCert.ValueString()),
+		ClientKeyPEM:       []byte(config.ClientKey.ValueString()),
+		ProxyURL:           config.ProxyURL.ValueString(),
+		InsecureSkipVerify: config.InsecureSkipVerify.ValueBool(),
+	}
+	if !config.CACertFile.IsNull() {
+		caCertPEM, err := os.ReadFile(config.CACertFile.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("ca_cert_file"),
+				"Unable to Read CA Certificate File",
+				"Could not read the CA certificates: "+err.Error(),
+			)
+			return
+		}
+		transportOpts.CACertPEM = caCertPEM
+	}
+	if transportOpts.InsecureSkipVerify {
+		resp.Diagnostics.AddAttributeWarning(
+			path.Root("insecure_skip_verify"),
+			"TLS Certificate Verification Disabled",
+			"The provider does not verify the certificate of the servicepipe API. "+
+				"Anyone on the network path can impersonate the API and read the API token. "+
+				"Use ca_cert_pem or ca_cert_file to trust a custom CA instead.",
+		)
+		tflog.Warn(ctx, "TLS certificate verification of the servicepipe API is disabled")
+	}
+
 	// Create a new servicepipe client using the configuration values
-	client := v1.NewClientV1(config.Token.ValueString(), config.Endpoint.ValueString())
+	client, err := v1.NewClientV1WithTransportOpts(config.Token.ValueString(), config.Endpoint.ValueString(), transportOpts)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to Create servicepipe API Client",
+			"Could not configure TLS or the proxy of the servicepipe API client: "+err.Error(),
+		)
+		return
+	}
+	circuitBreakerThreshold := int64(v1.DefaultCircuitBreakerThreshold)
+	if !config.CircuitBreakerThreshold.IsNull() {
+		circuitBreakerThreshold = config.CircuitBreakerThreshold.ValueInt64()
+	}
+	circuitBreakerCoolDown := v1.DefaultCircuitBreakerCoolDown
+	if !config.CircuitBreakerCoolDown.IsNull() {
+		circuitBreakerCoolDown = time.Duration(config.CircuitBreakerCoolDown.ValueInt64()) * time.Second
+	}
+	if circuitBreakerThreshold > 0 {
+		client.CircuitBreaker = v1.NewCircuitBreaker(int(circuitBreakerThreshold), circuitBreakerCoolDown)
+	}
+
+	if config.AuditLogPath.IsNull() {
+		if err := p.auditLog.close(); err != nil {
+			tflog.Warn(ctx, "could not close the audit log", map[string]any{"error": err.Error()})
+		}
+	} else {
+		auditor, err := p.auditLog.open(config.AuditLogPath.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("audit_log_path"),
+				"Unable to Open Audit Log",
+				"Could not open the audit log: "+err.Error(),
+			)
+			return
+		}
+		client.Auditor = auditor
+	}
+
 	ok, _, err := client.Echo(ctx)
 	if err != nil {
 		resp.Diagnostics.AddError(
@@ -152,8 +327,18 @@ func (p *servicepipeProvider) Configure(ctx context.Context, req provider.Config
 
 	// Make the servicepipe client available during DataSource and Resource
 	// type Configure methods.
-	resp.DataSourceData = client
-	resp.ResourceData = client
+	maxParallelRequests := defaultMaxParallelRequests
+	if !config.MaxParallelRequests.IsNull() {
+		maxParallelRequests = int(config.MaxParallelRequests.ValueInt64())
+	}
+
+	providerData := &servicepipeProviderData{
+		client:              client,
+		deletionProtection:  config.DeletionProtection.ValueBool(),
+		maxParallelRequests: maxParallelRequests,
+	}
+	resp.DataSourceData = providerData
+	resp.ResourceData = providerData
 
 	tflog.Info(ctx, "Configured servicepipe client", map[string]any{"success": true})
 }
@@ -162,6 +347,7 @@ func (p *servicepipeProvider) Configure(ctx context.Context, req provider.Config
 func (p *servicepipeProvider) Resources(ctx context.Context) []func() resource.Resource {
 	return []func() resource.Resource{
 		NewL7resourceResource,
+		NewL7originResource,
 	}
 }
 
@@ -0,0 +1,86 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/netip"
+	"sort"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// hostResolver resolves origin hostnames to IP addresses.
+type hostResolver interface {
+	// LookupIP returns IPv4 and IPv6 addresses of the host.
+	LookupIP(ctx context.Context, host string) ([]netip.Addr, error)
+}
+
+// netHostResolver resolves hostnames with the system DNS resolver.
+type netHostResolver struct {
+	resolver *net.Resolver
+}
+
+// newNetHostResolver returns a hostResolver backed by the default net resolver.
+func newNetHostResolver() hostResolver {
+	return &netHostResolver{
+		resolver: net.DefaultResolver,
+	}
+}
+
+// LookupIP returns A and AAAA records of the host.
+func (r *netHostResolver) LookupIP(ctx context.Context, host string) ([]netip.Addr, error) {
+	return r.resolver.LookupNetIP(ctx, "ip", host)
+}
+
+// resolveHostname resolves the host into a sorted list of unique IP addresses.
+func resolveHostname(ctx context.Context, resolver hostResolver, host string) ([]string, error) {
+	addrs, err := resolver.LookupIP(ctx, host)
+	if err != nil {
+		return nil, fmt.Errorf("could not resolve origin hostname %s: %w", host, err)
+	}
+	if len(addrs) == 0 {
+		return nil, fmt.Errorf("origin hostname %s has no A or AAAA records", host)
+	}
+
+	seen := make(map[string]bool, len(addrs))
+	ips := make([]string, 0, len(addrs))
+	for _, addr := range addrs {
+		ip := addr.Unmap().String()
+		if seen[ip] {
+			continue
+		}
+		seen[ip] = true
+		ips = append(ips, ip)
+	}
+	sort.Strings(ips)
+
+	return ips, nil
+}
+
+// resolvedIPsValue converts resolved IP addresses into a list attribute value.
+func resolvedIPsValue(ips []string) types.List {
+	elements := make([]attr.Value, 0, len(ips))
+	for _, ip := range ips {
+		elements = append(elements, types.StringValue(ip))
+	}
+
+	return types.ListValueMust(types.StringType, elements)
+}
+
+// resolvedIPs returns IP addresses stored in a resolved_ips attribute value.
+func resolvedIPs(value types.List) []string {
+	if value.IsNull() || value.IsUnknown() {
+		return nil
+	}
+
+	ips := make([]string, 0, len(value.Elements()))
+	for _, element := range value.Elements() {
+		if ip, ok := element.(types.String); ok && !ip.IsNull() && !ip.IsUnknown() {
+			ips = append(ips, ip.ValueString())
+		}
+	}
+
+	return ips
+}
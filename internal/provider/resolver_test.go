@@ -0,0 +1,175 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"net/netip"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"terraform-provider-servicepipe/internal/pkg/sdkv1/l7origin"
+)
+
+// fakeHostResolver resolves hostnames from a fixed table, unknown hosts fail.
+type fakeHostResolver map[string][]string
+
+func (r fakeHostResolver) LookupIP(_ context.Context, host string) ([]netip.Addr, error) {
+	ips, ok := r[host]
+	if !ok {
+		return nil, errors.New("no such host")
+	}
+
+	addrs := make([]netip.Addr, 0, len(ips))
+	for _, ip := range ips {
+		addrs = append(addrs, netip.MustParseAddr(ip))
+	}
+
+	return addrs, nil
+}
+
+func TestResolveHostname(t *testing.T) {
+	resolver := fakeHostResolver{
+		"single.example.com":    {"198.51.100.1"},
+		"several.example.com":   {"198.51.100.2", "2001:db8::1", "198.51.100.1"},
+		"duplicate.example.com": {"198.51.100.1", "::ffff:198.51.100.1"},
+		"empty.example.com":     {},
+	}
+
+	tests := map[string]struct {
+		host    string
+		want    []string
+		wantErr bool
+	}{
+		"single address":     {host: "single.example.com", want: []string{"198.51.100.1"}},
+		"sorted addresses":   {host: "several.example.com", want: []string{"198.51.100.1", "198.51.100.2", "2001:db8::1"}},
+		"unmapped duplicate": {host: "duplicate.example.com", want: []string{"198.51.100.1"}},
+		"no records":         {host: "empty.example.com", wantErr: true},
+		"lookup error":       {host: "missing.example.com", wantErr: true},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := resolveHostname(context.Background(), resolver, test.host)
+			if test.wantErr {
+				if err == nil {
+					t.Fatalf("got %v, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+// newOriginTestModel returns a model of an origin with the IP or the hostname.
+func newOriginTestModel(ip, hostname types.String, weight int64, mode string) *l7originResourceModel {
+	return &l7originResourceModel{
+		L7ResourceID: types.Int64Null(),
+		ID:           types.Int64Null(),
+		Weight:       types.Int64Value(weight),
+		WeightRatio:  types.Int64Null(),
+		Mode:         types.StringValue(mode),
+		IP:           ip,
+		Hostname:     hostname,
+		ResolvedIPs:  types.ListNull(types.StringType),
+		CreatedAt:    types.Int64Null(),
+		ModifiedAt:   types.Int64Null(),
+	}
+}
+
+func TestL7resourceModifyPlanResolvedIPs(t *testing.T) {
+	resolver := fakeHostResolver{
+		"origin.example.com": {"198.51.100.2", "198.51.100.1"},
+		"backup.example.com": {"2001:db8::1"},
+	}
+
+	tests := map[string]struct {
+		origins         []*l7originResourceModel
+		wantResolvedIPs []types.List
+		wantItems       []*l7origin.Item
+	}{
+		"ip": {
+			origins: []*l7originResourceModel{
+				newOriginTestModel(types.StringValue("203.0.113.1"), types.StringNull(), 100, l7origin.ModePrimary),
+			},
+			wantResolvedIPs: []types.List{types.ListNull(types.StringType)},
+			wantItems: []*l7origin.Item{
+				{IP: "203.0.113.1", Weight: 100, Mode: l7origin.ModePrimary},
+			},
+		},
+		"hostname": {
+			origins: []*l7originResourceModel{
+				newOriginTestModel(types.StringNull(), types.StringValue("origin.example.com"), 50, l7origin.ModePrimary),
+			},
+			wantResolvedIPs: []types.List{resolvedIPsValue([]string{"198.51.100.1", "198.51.100.2"})},
+			wantItems: []*l7origin.Item{
+				{IP: "198.51.100.1", Weight: 50, Mode: l7origin.ModePrimary},
+				{IP: "198.51.100.2", Weight: 50, Mode: l7origin.ModePrimary},
+			},
+		},
+		"unknown hostname": {
+			origins: []*l7originResourceModel{
+				newOriginTestModel(types.StringNull(), types.StringUnknown(), 100, l7origin.ModePrimary),
+			},
+			wantResolvedIPs: []types.List{types.ListUnknown(types.StringType)},
+			wantItems:       []*l7origin.Item{},
+		},
+		"ip and hostnames": {
+			origins: []*l7originResourceModel{
+				newOriginTestModel(types.StringValue("203.0.113.1"), types.StringNull(), 40, l7origin.ModePrimary),
+				newOriginTestModel(types.StringNull(), types.StringValue("origin.example.com"), 30, l7origin.ModePrimary),
+				newOriginTestModel(types.StringNull(), types.StringValue("backup.example.com"), 1, l7origin.ModeBackup),
+			},
+			wantResolvedIPs: []types.List{
+				types.ListNull(types.StringType),
+				resolvedIPsValue([]string{"198.51.100.1", "198.51.100.2"}),
+				resolvedIPsValue([]string{"2001:db8::1"}),
+			},
+			wantItems: []*l7origin.Item{
+				{IP: "203.0.113.1", Weight: 40, Mode: l7origin.ModePrimary},
+				{IP: "198.51.100.1", Weight: 30, Mode: l7origin.ModePrimary},
+				{IP: "198.51.100.2", Weight: 30, Mode: l7origin.ModePrimary},
+				{IP: "2001:db8::1", Weight: 1, Mode: l7origin.ModeBackup},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			r := &l7resourceResource{resolver: resolver}
+
+			config := newL7resourceTestModel(types.StringValue("example.com"))
+			config.Origins = test.origins
+
+			plan := modifyL7resourcePlan(t, r, config, nil)
+			if len(plan.Origins) != len(test.wantResolvedIPs) {
+				t.Fatalf("got %d origins, want %d", len(plan.Origins), len(test.wantResolvedIPs))
+			}
+			for i, origin := range plan.Origins {
+				if !origin.ResolvedIPs.Equal(test.wantResolvedIPs[i]) {
+					t.Errorf("got resolved_ips %s of origin %d, want %s", origin.ResolvedIPs, i, test.wantResolvedIPs[i])
+				}
+			}
+
+			items := expandL7OriginModels(plan.Origins)
+			if len(items) != len(test.wantItems) {
+				t.Fatalf("got %d origin items, want %d", len(items), len(test.wantItems))
+			}
+			for i, item := range items {
+				want := test.wantItems[i]
+				if item.IP != want.IP || item.Weight != want.Weight || item.Mode != want.Mode {
+					t.Errorf("got origin item %s/%d/%s at %d, want %s/%d/%s",
+						item.IP, item.Weight, item.Mode, i, want.IP, want.Weight, want.Mode)
+				}
+			}
+		})
+	}
+}
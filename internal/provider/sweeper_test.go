@@ -0,0 +1,124 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+
+	v1 "terraform-provider-servicepipe/internal/pkg/sdkv1"
+	l7origin "terraform-provider-servicepipe/internal/pkg/sdkv1/l7origin"
+	l7resource "terraform-provider-servicepipe/internal/pkg/sdkv1/l7resource"
+)
+
+const (
+	// sweepPrefixEnv is the environment variable with the name prefix of
+	// domains removed by sweepers.
+	sweepPrefixEnv = "SERVICEPIPE_SWEEP_PREFIX"
+
+	// defaultSweepPrefix is the name prefix of domains created by acceptance
+	// tests.
+	defaultSweepPrefix = "tf-acc-"
+)
+
+// TestMain runs sweepers when go test is called with -sweep.
+func TestMain(m *testing.M) {
+	resource.TestMain(m)
+}
+
+func init() {
+	resource.AddTestSweepers("servicepipe_l7origin", &resource.Sweeper{
+		Name: "servicepipe_l7origin",
+		F:    sweepL7origins,
+	})
+
+	resource.AddTestSweepers("servicepipe_l7resource", &resource.Sweeper{
+		Name:         "servicepipe_l7resource",
+		Dependencies: []string{"servicepipe_l7origin"},
+		F:            sweepL7resources,
+	})
+}
+
+// sweepL7origins deletes origins of domains left by acceptance tests.
+func sweepL7origins(_ string) error {
+	ctx := context.Background()
+	client, items, err := sweepL7resourceItems(ctx)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, item := range items {
+		origins, _, err := l7origin.ListAll(ctx, client, &l7origin.ListOpts{L7ResourceID: item.L7ResourceID})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("could not list origins of l7 resource %s: %w", item.L7ResourceName, err))
+			continue
+		}
+
+		for _, origin := range origins {
+			_, _, err := l7origin.Delete(ctx, client, &l7origin.DeleteOpts{L7ResourceID: item.L7ResourceID, ID: origin.ID})
+			if err != nil {
+				errs = append(errs, fmt.Errorf("could not delete origin %s of l7 resource %s: %w", origin.IP, item.L7ResourceName, err))
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// sweepL7resources deletes domains left by acceptance tests.
+func sweepL7resources(_ string) error {
+	ctx := context.Background()
+	client, items, err := sweepL7resourceItems(ctx)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, item := range items {
+		_, _, err := l7resource.Delete(ctx, client, &l7resource.DeleteOpts{L7ResourceID: int(item.L7ResourceID)})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("could not delete l7 resource %s: %w", item.L7ResourceName, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// sweepL7resourceItems returns the API client and the domains whose names
+// start with the sweep prefix.
+func sweepL7resourceItems(ctx context.Context) (*v1.Client, []*l7resource.Item, error) {
+	token := os.Getenv("SERVICEPIPE_TOKEN")
+	if token == "" {
+		return nil, nil, errors.New("SERVICEPIPE_TOKEN must be set for sweepers")
+	}
+
+	client := v1.NewClientV1WithDefaultEndpoint(token)
+	if endpoint := os.Getenv("SERVICEPIPE_ENDPOINT"); endpoint != "" {
+		client = v1.NewClientV1(token, endpoint)
+	}
+
+	prefix := os.Getenv(sweepPrefixEnv)
+	if prefix == "" {
+		prefix = defaultSweepPrefix
+	}
+	prefix = strings.ToLower(prefix)
+
+	items, _, err := l7resource.ListAll(ctx, client, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not list l7 resources: %w", err)
+	}
+
+	var matched []*l7resource.Item
+	for _, item := range items {
+		if strings.HasPrefix(strings.ToLower(item.L7ResourceName), prefix) {
+			matched = append(matched, item)
+		}
+	}
+
+	return client, matched, nil
+}
@@ -0,0 +1,39 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName is the instrumentation name of spans started by the provider.
+const tracerName = "terraform-provider-servicepipe/internal/provider"
+
+// startOperationSpan starts the span of a resource operation, spans of API
+// requests made with the returned context are nested under it.
+func startOperationSpan(ctx context.Context, typeName, operation string) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, typeName+"."+operation,
+		trace.WithAttributes(
+			attribute.String("terraform.resource.type", typeName),
+			attribute.String("terraform.operation", operation),
+		),
+	)
+}
+
+// endOperationSpan records the errors of the operation and ends its span.
+func endOperationSpan(span trace.Span, diags diag.Diagnostics) {
+	for _, d := range diags.Errors() {
+		span.AddEvent("error", trace.WithAttributes(
+			attribute.String("summary", d.Summary()),
+			attribute.String("detail", d.Detail()),
+		))
+	}
+	if diags.HasError() {
+		span.SetStatus(codes.Error, diags.Errors()[0].Summary())
+	}
+	span.End()
+}
@@ -0,0 +1,58 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+)
+
+var _ validator.String = ipAddressValidator{}
+
+// ipAddressValidator validates that a string attribute is a valid IPv4 or IPv6
+// address.
+type ipAddressValidator struct{}
+
+// Description describes the validation in plain text formatting.
+func (v ipAddressValidator) Description(_ context.Context) string {
+	return "value must be a valid IPv4 or IPv6 address without a zone"
+}
+
+// MarkdownDescription describes the validation in Markdown formatting.
+func (v ipAddressValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+// ValidateString performs the validation.
+func (v ipAddressValidator) ValidateString(ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	value := req.ConfigValue.ValueString()
+	addr, err := netip.ParseAddr(value)
+	if err != nil || addr.Zone() != "" {
+		resp.Diagnostics.AddAttributeError(
+			req.Path,
+			"Invalid IP Address",
+			fmt.Sprintf("Attribute %s %s, got: %q", req.Path, v.Description(ctx), value),
+		)
+		return
+	}
+
+	// Origins are matched by the canonical form the API returns.
+	if addr.String() != value {
+		resp.Diagnostics.AddAttributeError(
+			req.Path,
+			"Invalid IP Address",
+			fmt.Sprintf("Attribute %s must be an IP address in canonical form, got: %q, use: %q", req.Path, value, addr.String()),
+		)
+	}
+}
+
+// isIPAddress returns a validator which ensures that any configured attribute
+// value is a valid IPv4 or IPv6 address.
+func isIPAddress() validator.String {
+	return ipAddressValidator{}
+}
@@ -0,0 +1,42 @@
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestIPAddressValidator(t *testing.T) {
+	tests := map[string]struct {
+		value   types.String
+		wantErr bool
+	}{
+		"ipv4":                {value: types.StringValue("198.51.100.1")},
+		"ipv6":                {value: types.StringValue("2001:db8::1")},
+		"ipv4-mapped ipv6":    {value: types.StringValue("::ffff:198.51.100.1")},
+		"null":                {value: types.StringNull()},
+		"unknown":             {value: types.StringUnknown()},
+		"hostname":            {value: types.StringValue("example.com"), wantErr: true},
+		"empty":               {value: types.StringValue(""), wantErr: true},
+		"ipv4 with prefix":    {value: types.StringValue("198.51.100.0/24"), wantErr: true},
+		"uppercase ipv6":      {value: types.StringValue("2001:DB8::1"), wantErr: true},
+		"uncompressed ipv6":   {value: types.StringValue("2001:db8:0::1"), wantErr: true},
+		"ipv6 with zone":      {value: types.StringValue("fe80::1%eth0"), wantErr: true},
+		"ipv4 with zero pads": {value: types.StringValue("198.051.100.1"), wantErr: true},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := validator.StringRequest{Path: path.Root("ip"), ConfigValue: test.value}
+			resp := &validator.StringResponse{}
+			isIPAddress().ValidateString(context.Background(), req, resp)
+
+			if got := resp.Diagnostics.HasError(); got != test.wantErr {
+				t.Errorf("got error %t, want %t: %v", got, test.wantErr, resp.Diagnostics)
+			}
+		})
+	}
+}
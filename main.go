@@ -8,6 +8,7 @@ import (
 	"flag"
 	"log"
 
+	"terraform-provider-servicepipe/internal/pkg/tracing"
 	"terraform-provider-servicepipe/internal/provider"
 
 	"github.com/hashicorp/terraform-plugin-framework/providerserver"
@@ -44,7 +45,20 @@ func main() {
 		Debug:   debug,
 	}
 
-	err := providerserver.Serve(context.Background(), provider.New(version), opts)
+	// Spans are exported with OTLP when OTEL_EXPORTER_OTLP_* environment
+	// variables are set, tracing is a no-op otherwise.
+	// The provider works without tracing, so a broken tracing configuration
+	// doesn't stop it.
+	shutdownTracing, err := tracing.Setup(context.Background(), "terraform-provider-servicepipe", version)
+	if err != nil {
+		log.Printf("[WARN] could not set up tracing, continuing without it: %s", err)
+		shutdownTracing = func(context.Context) error { return nil }
+	}
+
+	err = providerserver.Serve(context.Background(), provider.New(version), opts)
+	if shutdownErr := shutdownTracing(context.Background()); shutdownErr != nil {
+		log.Printf("[WARN] could not flush traces: %s", shutdownErr)
+	}
 
 	if err != nil {
 		log.Fatal(err.Error())